@@ -0,0 +1,66 @@
+package ab
+
+import "slinky/board"
+
+// Move ordering scores, the best moves are searched first so that cutoffs happen early
+const (
+	ttMoveScore    = 2000000
+	captureScore   = 1000000 // plus the MVV-LVA score of the capture
+	firstKiller    = 900000
+	secondKiller   = 800000
+	promotionScore = 700000
+)
+
+// isQuiet returns true if the move neither captures nor promotes
+func isQuiet(move int) bool {
+	return board.Captured(move) == board.Empty && move&board.MoveFlagEnPass == 0 &&
+		board.Promoted(move) == board.Empty
+}
+
+// scoreMoves fills scores with the scores of the moves of the list for the move ordering:
+// the move from the transposition table, captures (MVV-LVA), killers, promotions and
+// then quiet moves by their history score
+func (s *Searcher) scoreMoves(moveList *board.MoveList, scores *[board.MaxPositionMoves]int, ttMove, ply int) {
+	for i := 0; i < moveList.Count; i++ {
+		move := moveList.Moves[i]
+		attacker := s.pos.Pieces[board.FromSq(move)]
+		switch {
+		case move == ttMove:
+			scores[i] = ttMoveScore
+		case board.Captured(move) != board.Empty:
+			scores[i] = captureScore + board.MvvLvaRank[board.Captured(move)]*6 - board.MvvLvaRank[attacker]
+		case move&board.MoveFlagEnPass != 0:
+			scores[i] = captureScore + board.MvvLvaRank[board.WhitePawn]*6 - board.MvvLvaRank[board.WhitePawn]
+		case move == s.killers[ply][0]:
+			scores[i] = firstKiller
+		case move == s.killers[ply][1]:
+			scores[i] = secondKiller
+		case board.Promoted(move) != board.Empty:
+			scores[i] = promotionScore
+		default:
+			scores[i] = s.history[attacker][board.ToSq(move)]
+		}
+	}
+}
+
+// pickMove moves the best scored move of the remaining moves (from index start on)
+// to index start and returns it. This way only the moves that are searched get sorted
+func pickMove(moveList *board.MoveList, scores *[board.MaxPositionMoves]int, start int) int {
+	best := start
+	for i := start + 1; i < moveList.Count; i++ {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	moveList.Moves[start], moveList.Moves[best] = moveList.Moves[best], moveList.Moves[start]
+	scores[start], scores[best] = scores[best], scores[start]
+	return moveList.Moves[start]
+}
+
+// updateKillers stores a quiet move that caused a cutoff as the first killer of the ply
+func (s *Searcher) updateKillers(ply, move int) {
+	if s.killers[ply][0] != move {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = move
+	}
+}
@@ -0,0 +1,415 @@
+// Package ab implements an alpha-beta (principal variation) search as an alternative to the UCT search
+package ab
+
+import (
+	"context"
+	"slinky/board"
+	"slinky/eval"
+	"time"
+)
+
+// Scores of the search in centipawns
+const (
+	Infinity  = 30000
+	MateScore = 29000                // score of a mate at the root, mate in n plies scores MateScore - n
+	MateBound = MateScore - MaxDepth // scores above MateBound (below -MateBound) are mates
+)
+
+// MaxDepth maximum search depth in plies (including the quiescence search)
+const MaxDepth = 64
+
+const (
+	nullMoveReduction = 2    // depth reduction of the null move search
+	checkStopInterval = 2047 // the search checks if it has to stop every checkStopInterval+1 nodes
+)
+
+// Result holds the outcome of one iteration of the iterative deepening
+type Result struct {
+	Move       int
+	PonderMove int    // expected reply to the best move (board.NoMove if unknown)
+	Score      int    // score in centipawns from the point of view of the side to move
+	Mate       int    // moves until mate (negative if the side to move gets mated), 0 if no mate was found
+	WDL        [3]int // win, draw and loss probability in permille (see WDL)
+	Depth      int    // depth of the completed iteration
+	SelDepth   int    // deepest ply reached (including the quiescence search)
+	Nodes      uint64 // number of nodes searched so far
+	HashFull   int    // usage of the transposition table in permille
+	PV         []int
+}
+
+// Searcher runs alpha-beta searches. The transposition table and the history are
+// kept between searches
+type Searcher struct {
+	HashSize      int  // size of the transposition table in MB
+	Contempt      int  // centipawns by which the engine values a draw below an even position (negative - draws are welcome)
+	ContemptPhase bool // scale the contempt with the game phase (see eval.Contempt)
+
+	tt       *transpositionTable
+	pos      *board.ChessBoard
+	ctx      context.Context
+	stopped  bool
+	nodes    uint64
+	maxNodes uint64 // node limit of the search (0 - no limit)
+	selDepth int
+	killers  [MaxDepth][2]int
+	history  [board.PieceNum][board.BoardSquareNum]int // indexed by moving piece and target square
+	pv       [MaxDepth][MaxDepth]int                   // triangular principal variation table
+	pvLength [MaxDepth]int
+	draw     [2]int // score of a draw for each side, the side to move at the root is the engine
+	roots    []int  // moves searched at the root (nil - all legal moves)
+}
+
+// DefaultHashSize size of the transposition table in MB if none is set
+const DefaultHashSize = 16
+
+// NewSearcher creates a searcher with a transposition table of DefaultHashSize MB
+func NewSearcher() *Searcher {
+	s := &Searcher{HashSize: DefaultHashSize}
+	s.Clear()
+	return s
+}
+
+// Clear discards the transposition table and the history (i.e. when a new game starts)
+func (s *Searcher) Clear() {
+	s.tt = newTranspositionTable(s.HashSize * 1024 * 1024)
+	s.history = [board.PieceNum][board.BoardSquareNum]int{}
+}
+
+// Search runs an iterative deepening search until ctx is cancelled, the time set in
+// info runs out, the node limit of info is reached or the depth set in info is completed. report (if not nil) is called
+// with the result of every completed iteration. Only the root moves of info.SearchMoves are searched if it has any
+func (s *Searcher) Search(ctx context.Context, pos *board.ChessBoard, info *board.SearchInfo, report func(Result)) Result {
+	if info.TimeSet && !info.Ponder && !info.Infinite {
+		var cancel context.CancelFunc
+		stopTime := info.StartTime.Add(time.Duration(info.StopTime) * time.Millisecond)
+		ctx, cancel = context.WithDeadline(ctx, stopTime)
+		defer cancel()
+	}
+
+	if s.tt == nil || s.tt.size != s.HashSize*1024*1024 || info.Seed != 0 {
+		// a seeded search is reproduced from scratch (see board.SearchInfo.Seed)
+		s.Clear()
+	}
+	s.tt.age++
+
+	searchPos := *pos
+	s.pos = &searchPos
+	s.ctx = ctx
+	s.stopped = false
+	s.nodes = 0
+	s.maxNodes = info.MaxNodes
+	s.selDepth = 0
+	s.killers = [MaxDepth][2]int{}
+	contempt := eval.Contempt(pos, s.Contempt, s.ContemptPhase)
+	s.draw[pos.Side] = -contempt
+	s.draw[pos.Side^1] = contempt
+
+	maxDepth := MaxDepth - 1
+	if info.Depth > 0 && info.Depth < maxDepth {
+		maxDepth = info.Depth
+	}
+
+	result := Result{Move: board.NoMove, PonderMove: board.NoMove}
+	moves := s.pos.RootMoves(info.SearchMoves)
+	if len(moves) > 0 {
+		result.Move = moves[0] // in case not even the first iteration completes
+	}
+	s.roots = nil
+	if len(moves) < len(s.pos.GetMoves()) {
+		s.roots = moves
+	}
+
+	for depth := 1; depth <= maxDepth; depth++ {
+		score := s.alphaBeta(-Infinity, Infinity, depth, 0, true)
+		if s.stopped {
+			break
+		}
+
+		result.Score = score
+		result.Mate = mateMoves(score)
+		result.WDL = WDL(score)
+		result.Depth = depth
+		result.SelDepth = s.selDepth
+		result.Nodes = s.nodes
+		result.HashFull = s.tt.hashFull()
+		result.PV = append([]int{}, s.pv[0][:s.pvLength[0]]...)
+		if len(result.PV) > 0 {
+			result.Move = result.PV[0]
+		}
+		result.PonderMove = board.NoMove
+		if len(result.PV) > 1 {
+			result.PonderMove = result.PV[1]
+		}
+		if report != nil {
+			report(result)
+		}
+
+		// a mate was found that can not be improved by searching deeper
+		if result.Mate != 0 && MateScore-abs(score) <= depth {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	return result
+}
+
+// mateMoves converts a score to the number of moves until mate (0 if the score is not a mate)
+func mateMoves(score int) int {
+	if score > MateBound {
+		return (MateScore - score + 1) / 2
+	} else if score < -MateBound {
+		return -(MateScore + score) / 2
+	}
+	return 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// checkStop marks the search as stopped once ctx is cancelled or the node limit is reached
+func (s *Searcher) checkStop() {
+	if s.maxNodes > 0 && s.nodes >= s.maxNodes {
+		s.stopped = true
+	} else if s.nodes&checkStopInterval == 0 && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+}
+
+// isRootMove returns true if the move is searched at the root
+func (s *Searcher) isRootMove(move int) bool {
+	if s.roots == nil {
+		return true
+	}
+	for _, root := range s.roots {
+		if root == move {
+			return true
+		}
+	}
+	return false
+}
+
+// isDraw returns true if the position is a draw by the fifty move rule, repetition
+// or insufficient material
+func (s *Searcher) isDraw() bool {
+	return s.pos.IsFiftyMoveDraw() || s.pos.IsRepetition() || s.pos.IsPositionDraw()
+}
+
+// hasPieces returns true if side has pieces other than pawns and the king. Null
+// moves are not tried without pieces since zugzwang is common in pawn endings
+func hasPieces(pos *board.ChessBoard, side int) bool {
+	pieces := []int{board.WhiteKnight, board.WhiteBishop, board.WhiteRook, board.WhiteQueen}
+	if side == board.Black {
+		pieces = []int{board.BlackKnight, board.BlackBishop, board.BlackRook, board.BlackQueen}
+	}
+	for _, piece := range pieces {
+		if pos.PieceCount(piece) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// alphaBeta is a principal variation search. It returns the score of the position
+// from the point of view of the side to move within the window alpha-beta
+func (s *Searcher) alphaBeta(alpha, beta, depth, ply int, nullAllowed bool) int {
+	s.pvLength[ply] = ply
+	if depth <= 0 {
+		return s.quiescence(alpha, beta, ply)
+	}
+
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return 0
+	}
+	if ply > s.selDepth {
+		s.selDepth = ply
+	}
+
+	pos := s.pos
+	if ply > 0 && s.isDraw() {
+		return s.draw[pos.Side]
+	}
+	if ply >= MaxDepth-1 {
+		return eval.Evaluate(pos)
+	}
+
+	inCheck := pos.InCheck()
+	if inCheck {
+		depth++ // check extension
+	}
+
+	pvNode := beta-alpha > 1
+	ttMove := board.NoMove
+	if entry, found := s.tt.probe(pos.PosKey()); found {
+		ttMove = int(entry.move)
+		score := scoreFromTT(int(entry.score), ply)
+		if !pvNode && int(entry.depth) >= depth {
+			switch {
+			case entry.bound == boundExact,
+				entry.bound == boundLower && score >= beta,
+				entry.bound == boundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	// null move pruning: if passing the turn still fails high, a real move will too
+	if nullAllowed && !pvNode && !inCheck && depth > nullMoveReduction && ply > 0 &&
+		hasPieces(pos, pos.Side) && eval.Evaluate(pos) >= beta {
+		pos.MakeNullMove()
+		score := -s.alphaBeta(-beta, -beta+1, depth-1-nullMoveReduction, ply+1, false)
+		pos.TakeNullMove()
+		if s.stopped {
+			return 0
+		}
+		if score >= beta && score < MateBound {
+			return beta
+		}
+	}
+
+	var moveList board.MoveList
+	pos.GenerateAllMoves(&moveList)
+	var scores [board.MaxPositionMoves]int
+	s.scoreMoves(&moveList, &scores, ttMove, ply)
+
+	oldAlpha := alpha
+	bestMove := board.NoMove
+	legalMoves := 0
+	for i := 0; i < moveList.Count; i++ {
+		move := pickMove(&moveList, &scores, i)
+		if !pos.IsMoveLegal(move) || (ply == 0 && !s.isRootMove(move)) {
+			continue
+		}
+		legalMoves++
+		quiet := isQuiet(move)
+		killer := move == s.killers[ply][0] || move == s.killers[ply][1]
+
+		pos.MakeMove(move)
+		var score int
+		if legalMoves == 1 {
+			score = -s.alphaBeta(-beta, -alpha, depth-1, ply+1, true)
+		} else {
+			// late move reductions: quiet moves ordered late are searched less deep first
+			reduction := 0
+			if depth >= 3 && legalMoves > 3 && quiet && !killer && !inCheck && !pos.InCheck() {
+				reduction = 1
+				if legalMoves > 6 {
+					reduction = 2
+				}
+			}
+			// the moves after the first one are expected to be worse -> prove it with a null window
+			score = -s.alphaBeta(-alpha-1, -alpha, depth-1-reduction, ply+1, true)
+			if score > alpha && reduction > 0 {
+				score = -s.alphaBeta(-alpha-1, -alpha, depth-1, ply+1, true)
+			}
+			if score > alpha && score < beta {
+				score = -s.alphaBeta(-beta, -alpha, depth-1, ply+1, true)
+			}
+		}
+		pos.TakeMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			bestMove = move
+			s.updatePV(ply, move)
+
+			if score >= beta {
+				if quiet {
+					s.updateKillers(ply, move)
+					s.history[pos.Pieces[board.FromSq(move)]][board.ToSq(move)] += depth * depth
+				}
+				s.tt.store(pos.PosKey(), move, beta, depth, boundLower, ply)
+				return beta
+			}
+		}
+	}
+
+	if legalMoves == 0 {
+		if inCheck {
+			return -MateScore + ply
+		}
+		return s.draw[pos.Side] // stalemate
+	}
+
+	if alpha > oldAlpha {
+		s.tt.store(pos.PosKey(), bestMove, alpha, depth, boundExact, ply)
+	} else {
+		s.tt.store(pos.PosKey(), board.NoMove, alpha, depth, boundUpper, ply)
+	}
+	return alpha
+}
+
+// quiescence searches only captures and promotions until the position is quiet, so
+// that the static evaluation is not taken in the middle of an exchange
+func (s *Searcher) quiescence(alpha, beta, ply int) int {
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return 0
+	}
+	if ply > s.selDepth {
+		s.selDepth = ply
+	}
+
+	pos := s.pos
+	if s.isDraw() {
+		return s.draw[pos.Side]
+	}
+
+	standPat := eval.Evaluate(pos)
+	if ply >= MaxDepth-1 || standPat >= beta {
+		return standPat
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	var moveList board.MoveList
+	pos.GenerateAllMoves(&moveList)
+	var scores [board.MaxPositionMoves]int
+	s.scoreMoves(&moveList, &scores, board.NoMove, ply)
+
+	for i := 0; i < moveList.Count; i++ {
+		move := pickMove(&moveList, &scores, i)
+		if isQuiet(move) || !pos.IsMoveLegal(move) {
+			continue
+		}
+
+		pos.MakeMove(move)
+		score := -s.quiescence(-beta, -alpha, ply+1)
+		pos.TakeMove()
+
+		if s.stopped {
+			return 0
+		}
+		if score > alpha {
+			if score >= beta {
+				return beta
+			}
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// updatePV sets the principal variation of the ply to the move followed by the variation of the next ply
+func (s *Searcher) updatePV(ply, move int) {
+	s.pv[ply][ply] = move
+	next := ply + 1
+	if next < MaxDepth {
+		copy(s.pv[ply][next:s.pvLength[next]], s.pv[next][next:s.pvLength[next]])
+		s.pvLength[ply] = s.pvLength[next]
+	} else {
+		s.pvLength[ply] = next
+	}
+}
@@ -0,0 +1,92 @@
+package ab
+
+import (
+	"context"
+	"slinky/board"
+	"testing"
+)
+
+// TestSearchFindsBestMove checks mates and a simple tactic at a fixed depth
+func TestSearchFindsBestMove(t *testing.T) {
+	board.AllInit()
+
+	tests := []struct {
+		fen   string
+		depth int
+		move  string
+		mate  int
+	}{
+		{"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", 2, "d1d8", 1},
+		{"7k/8/5K2/8/8/8/8/6R1 w - - 0 1", 6, "f6f7", 2},
+		{"7k/8/8/8/8/1r6/r7/6K1 w - - 0 1", 4, "", -1},                                     // mated whatever we do
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/3PP3/5N2/PPP2PPP/RNBQKB1R b KQkq - 0 3", 4, "e5d4", 0}, // win back the pawn
+	}
+
+	for _, test := range tests {
+		pos := board.CreateBoard()
+		pos.ParseFen(test.fen)
+		info := board.SearchInfo{Depth: test.depth}
+
+		result := NewSearcher().Search(context.Background(), &pos, &info, nil)
+		if test.move != "" && board.PrintMove(result.Move) != test.move {
+			t.Errorf("%s: expected move %s, got %s (pv %v)", test.fen, test.move, board.PrintMove(result.Move), result.PV)
+		}
+		if result.Mate != test.mate {
+			t.Errorf("%s: expected mate %d, got %d (score %d)", test.fen, test.mate, result.Mate, result.Score)
+		}
+		if result.Depth > test.depth {
+			t.Errorf("%s: searched depth %d, expected at most %d", test.fen, result.Depth, test.depth)
+		}
+	}
+}
+
+// TestWDL checks that the win/draw/loss probabilities add up and follow the score
+func TestWDL(t *testing.T) {
+	last := WDL(-Infinity)
+	for score := -2000; score <= 2000; score += 50 {
+		wdl := WDL(score)
+		if wdl[0]+wdl[1]+wdl[2] != 1000 || wdl[1] < 0 {
+			t.Errorf("invalid wdl %v for score %d", wdl, score)
+		}
+		if wdl[0] < last[0] || wdl[2] > last[2] {
+			t.Errorf("wdl %v for score %d is not monotonic (previous %v)", wdl, score, last)
+		}
+		last = wdl
+	}
+	if wdl := WDL(MateScore - 3); wdl != [3]int{1000, 0, 0} {
+		t.Errorf("expected a certain win for a mate score, got %v", wdl)
+	}
+}
+
+// TestSearchContempt checks that a drawn position scores minus the contempt for the engine
+func TestSearchContempt(t *testing.T) {
+	board.AllInit()
+	// every move leaves insufficient material on the board
+	pos := board.CreateBoard()
+	pos.ParseFen("8/8/8/4k3/8/8/8/4K2N w - - 0 1")
+
+	for _, contempt := range []int{0, 30, -20} {
+		searcher := NewSearcher()
+		searcher.Contempt = contempt
+		result := searcher.Search(context.Background(), &pos, &board.SearchInfo{Depth: 3}, nil)
+		if result.Score != -contempt {
+			t.Errorf("contempt %d: expected a score of %d, got %d", contempt, -contempt, result.Score)
+		}
+	}
+}
+
+// TestSearchMoves checks that only the searchmoves are searched at the root
+func TestSearchMoves(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	searchMoves := []int{pos.ParseMove("d1d2"), pos.ParseMove("g1f1")}
+
+	result := NewSearcher().Search(context.Background(), &pos, &board.SearchInfo{Depth: 3, SearchMoves: searchMoves}, nil)
+	if result.Move != searchMoves[0] && result.Move != searchMoves[1] {
+		t.Errorf("expected one of the searchmoves, got %s", board.PrintMove(result.Move))
+	}
+	if result.Mate != 0 {
+		t.Errorf("expected no mate without d1d8, got mate %d", result.Mate)
+	}
+}
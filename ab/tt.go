@@ -0,0 +1,107 @@
+package ab
+
+import (
+	"slinky/board"
+	"unsafe"
+)
+
+// Bound of a score stored in the transposition table
+const (
+	boundExact uint8 = iota
+	boundLower       // the score failed high, the real score is at least the stored one
+	boundUpper       // the score failed low, the real score is at most the stored one
+)
+
+// ttEntry is the result of a search of a position
+type ttEntry struct {
+	key   uint64
+	move  int32
+	score int16
+	depth int8
+	bound uint8
+	age   uint8 // search that stored the entry (older entries are replaced first)
+}
+
+// transpositionTable stores the results of searched positions, one entry per slot.
+// A slot is replaced if the new entry is searched at least as deep or if the old
+// entry was stored by an earlier search
+type transpositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	age     uint8
+	size    int // size of the table in bytes as requested on creation
+}
+
+// newTranspositionTable creates a table that uses at most size bytes (at least one entry)
+func newTranspositionTable(size int) *transpositionTable {
+	entries := 1
+	for entries*2*int(unsafe.Sizeof(ttEntry{})) <= size {
+		entries *= 2
+	}
+	return &transpositionTable{
+		entries: make([]ttEntry, entries),
+		mask:    uint64(entries - 1),
+		size:    size,
+	}
+}
+
+// probe returns the entry of the position and if it was found
+func (tt *transpositionTable) probe(key uint64) (ttEntry, bool) {
+	entry := tt.entries[key&tt.mask]
+	return entry, entry.key == key && entry.depth > 0
+}
+
+// store saves the result of a search. Mate scores are stored relative to the
+// position (not to the root) so they stay valid when the position is reached at another ply
+func (tt *transpositionTable) store(key uint64, move, score, depth int, bound uint8, ply int) {
+	slot := &tt.entries[key&tt.mask]
+	if slot.key != key && slot.age == tt.age && int(slot.depth) > depth {
+		return
+	}
+	if move == board.NoMove && slot.key == key {
+		move = int(slot.move) // keep the best move of a previous search of the position
+	}
+	*slot = ttEntry{
+		key:   key,
+		move:  int32(move),
+		score: int16(scoreToTT(score, ply)),
+		depth: int8(depth),
+		bound: bound,
+		age:   tt.age,
+	}
+}
+
+// hashFull returns the part of the table used by the current search in permille
+func (tt *transpositionTable) hashFull() int {
+	samples := 1000
+	if len(tt.entries) < samples {
+		samples = len(tt.entries)
+	}
+	used := 0
+	for _, entry := range tt.entries[:samples] {
+		if entry.depth > 0 && entry.age == tt.age {
+			used++
+		}
+	}
+	return used * 1000 / samples
+}
+
+// scoreToTT converts a mate score from "mate in n plies from the root" to "mate in n plies from the position"
+func scoreToTT(score, ply int) int {
+	if score > MateBound {
+		return score + ply
+	} else if score < -MateBound {
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTT converts a stored mate score back to a score relative to the root
+func scoreFromTT(score, ply int) int {
+	if score > MateBound {
+		return score - ply
+	} else if score < -MateBound {
+		return score + ply
+	}
+	return score
+}
@@ -0,0 +1,24 @@
+package ab
+
+import "math"
+
+// Parameters of the win/draw/loss model. The expected score follows the same logistic
+// curve as the UCT search (a score of wdlScale centipawns is a ~91% expected score),
+// positions within about wdlDrawMargin centipawns of equality are mostly drawn
+const (
+	wdlScale      = 400
+	wdlDrawMargin = 100
+)
+
+// WDL converts a score in centipawns (from the point of view of the side to move) to
+// win, draw and loss probabilities in permille. Mate scores are certain wins or losses
+func WDL(score int) [3]int {
+	if score > MateBound {
+		return [3]int{1000, 0, 0}
+	} else if score < -MateBound {
+		return [3]int{0, 0, 1000}
+	}
+	win := int(math.Round(1000 / (1 + math.Pow(10, -float64(score-wdlDrawMargin)/wdlScale))))
+	loss := int(math.Round(1000 / (1 + math.Pow(10, -float64(-score-wdlDrawMargin)/wdlScale))))
+	return [3]int{win, 1000 - win - loss, loss}
+}
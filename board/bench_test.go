@@ -0,0 +1,49 @@
+package board
+
+import "testing"
+
+// benchFen is a middlegame position with many pieces and both sides able to castle
+const benchFen = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+func benchBoard() ChessBoard {
+	AllInit()
+	boardState := CreateBoard()
+	boardState.ParseFen(benchFen)
+	return boardState
+}
+
+func BenchmarkGenerateAllMoves(b *testing.B) {
+	boardState := benchBoard()
+	var moveList MoveList
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		moveList.Count = 0
+		boardState.GenerateAllMoves(&moveList)
+	}
+}
+
+func BenchmarkIsSquareAttacked(b *testing.B) {
+	boardState := benchBoard()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for sq64 := 0; sq64 < InnerSquareNum; sq64++ {
+			boardState.IsSquareAttacked(Sq64ToSq120[sq64], Black)
+		}
+	}
+}
+
+func BenchmarkMakeTakeMove(b *testing.B) {
+	boardState := benchBoard()
+	var moveList MoveList
+	boardState.GenerateAllMoves(&moveList)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < moveList.Count; j++ {
+			boardState.MakeMove(moveList.Moves[j])
+			boardState.TakeMove()
+		}
+	}
+}
@@ -24,7 +24,7 @@ type ChessBoard struct {
 	histPly    int                // how many half moves have been made
 	castlePerm int                // castle permissions
 	posKey     uint64             // position key is a unique key stored for each position (used to keep track of 3fold repetition)
-	pieceNum   [13]int            // how many pieces of each type are there currently on the board
+	pieceNum   [PieceNum]int      // how many pieces of each type are there currently on the board
 	history    [MaxGameMoves]Undo // array that stores current position and variables before a move is made
 
 	PlayerJustMoved int // At the root pretend the player just moved is Black i.e. White has the first move
@@ -75,6 +75,21 @@ func (pos *ChessBoard) GetPlayerJustMoved() int {
 	return pos.PlayerJustMoved
 }
 
+// PosKey returns the unique hashkey of the current position
+func (pos *ChessBoard) PosKey() uint64 {
+	return pos.posKey
+}
+
+// PieceCount returns how many pieces of the given type are on the board
+func (pos *ChessBoard) PieceCount(piece int) int {
+	return pos.pieceNum[piece]
+}
+
+// InCheck returns true if the side to move is in check
+func (pos *ChessBoard) InCheck() bool {
+	return pos.IsSquareAttacked(pos.kingSquare[pos.Side], pos.Side^1)
+}
+
 // UpdateListsMaterial updates all material related piece lists
 func (pos *ChessBoard) UpdateListsMaterial() {
 	for index := 0; index < BoardSquareNum; index++ {
@@ -192,18 +207,35 @@ func (pos *ChessBoard) String() string {
 	return line
 }
 
-// GetThreeFoldRepetitionCount Detects how many repetitions for a given position
+// GetThreeFoldRepetitionCount Detects how many repetitions for a given position. Only the
+// positions since the last capture or pawn move are checked since no earlier position can repeat
 func (pos *ChessBoard) GetThreeFoldRepetitionCount() int {
 	r := 0
 
-	for i := 0; i < pos.histPly; i++ {
-		if pos.history[i].posKey == pos.posKey {
+	for i := pos.histPly - pos.fiftyMove; i < pos.histPly; i++ {
+		if i >= 0 && pos.history[i].posKey == pos.posKey {
 			r++
 		}
 	}
 	return r
 }
 
+// IsRepetition returns true if the position occurred before. Only the positions since
+// the last capture or pawn move are checked since no earlier position can repeat
+func (pos *ChessBoard) IsRepetition() bool {
+	for i := pos.histPly - pos.fiftyMove; i < pos.histPly; i++ {
+		if i >= 0 && pos.history[i].posKey == pos.posKey {
+			return true
+		}
+	}
+	return false
+}
+
+// FiftyMove returns the number of half moves since the last capture or pawn move
+func (pos *ChessBoard) FiftyMove() int {
+	return pos.fiftyMove
+}
+
 // IsPositionDraw determine if position is a draw
 func (pos *ChessBoard) IsPositionDraw() bool {
 	// if there are pawns on the board the one of the sides can get mated
@@ -230,39 +262,67 @@ func (pos *ChessBoard) IsPositionDraw() bool {
 	return true
 }
 
-// GetResult is called everytime a move is made this function is called to check if the game is over
-func (pos *ChessBoard) GetResult(playerJM int) Result {
+// IsFiftyMoveDraw returns true if fifty moves (100 half moves) were made by each side
+// without a capture or a pawn move
+func (pos *ChessBoard) IsFiftyMoveDraw() bool {
+	return pos.fiftyMove >= 100
+}
 
-	if pos.fiftyMove > 100 {
-		// fmt.Printf("1/2-1/2 {fifty move rule (claimed by Slinky)}\n")
-		return Draw
+// IsDrawn returns true if the game is drawn by the fifty move rule, threefold repetition
+// or insufficient material
+func (pos *ChessBoard) IsDrawn() bool {
+	return pos.IsFiftyMoveDraw() || pos.GetThreeFoldRepetitionCount() >= 2 || pos.IsPositionDraw()
+}
+
+// HasLegalMove returns true if the side to move has at least one legal move
+func (pos *ChessBoard) HasLegalMove() bool {
+	var moveList MoveList
+	pos.GenerateAllMoves(&moveList)
+	for i := 0; i < moveList.Count; i++ {
+		if pos.IsMoveLegal(moveList.Moves[i]) {
+			return true
+		}
 	}
+	return false
+}
 
-	if pos.GetThreeFoldRepetitionCount() >= 2 {
-		// fmt.Printf("1/2-1/2 {3-fold repetition (claimed by Slinky)}\n")
-		return Draw
+// NoMovesResult returns the result of a game in which the side to move has no legal
+// moves (checkmate or stalemate) from the point of view of playerJM
+func (pos *ChessBoard) NoMovesResult(playerJM int) Result {
+	if pos.InCheck() {
+		if pos.Side == playerJM { // if i am the side in mate -> loss, else win
+			return Loss
+		}
+		return Win
 	}
+	// not in check but no legal moves left -> stalemate
+	return Draw
+}
 
-	if pos.IsPositionDraw() == true {
-		// fmt.Printf("1/2-1/2 {insufficient material (claimed by Slinky)}\n")
+// GetResult is called everytime a move is made this function is called to check if the game is over
+func (pos *ChessBoard) GetResult(playerJM int) Result {
+	if pos.IsDrawn() {
 		return Draw
 	}
-
-	if len(pos.GetMoves()) != 0 {
+	if pos.HasLegalMove() {
 		return NoWinner
 	}
+	return pos.NoMovesResult(playerJM)
+}
 
-	InCheck := pos.IsSquareAttacked(pos.kingSquare[pos.Side], pos.Side^1)
-
-	if InCheck == true {
-		if pos.Side == playerJM { // if i am the side in mate -> loss, else win
-			// fmt.Printf("0-1 {black mates (claimed by Slinky)}\n")
-			return Loss
+// RandomLegalMove picks a random legal move from the pseudo-legal moves of moveList.
+// The legality is only tested for the sampled moves: illegal ones are removed from
+// the list and another move is sampled. intn returns a random number in [0, n).
+// NoMove is returned if the position has no legal move
+func (pos *ChessBoard) RandomLegalMove(moveList *MoveList, intn func(n int) int) int {
+	for moveList.Count > 0 {
+		i := intn(moveList.Count)
+		move := moveList.Moves[i]
+		if pos.IsMoveLegal(move) {
+			return move
 		}
-		// fmt.Printf("0-1 {white mates (claimed by Slinky)}\n")
-		return Win
+		moveList.Count--
+		moveList.Moves[i] = moveList.Moves[moveList.Count]
 	}
-	// not in check but no legal moves left -> stalemate
-	// fmt.Printf("\n1/2-1/2 {stalemate (claimed by Slinky)}\n")
-	return Draw
+	return NoMove
 }
@@ -2,6 +2,7 @@ package board
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -37,3 +38,135 @@ func TestBoardCopy(t *testing.T) {
 		t.Errorf("Copying failed. History items match.")
 	}
 }
+
+func TestNullMove(t *testing.T) {
+	AllInit()
+	boardState := CreateBoard()
+	boardState.ParseFen("rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2")
+	key := boardState.PosKey()
+
+	boardState.MakeNullMove()
+	if boardState.Side != Black || boardState.enPas != NoSquare {
+		t.Errorf("Null move failed. Side %d, en passant square %d", boardState.Side, boardState.enPas)
+	}
+	if boardState.PosKey() != GeneratePosKey(&boardState) {
+		t.Errorf("Null move failed. Position key does not match the position")
+	}
+
+	boardState.TakeNullMove()
+	if boardState.Side != White || boardState.PosKey() != key || boardState.enPas == NoSquare {
+		t.Errorf("Taking back the null move failed")
+	}
+}
+
+func TestIsRepetition(t *testing.T) {
+	AllInit()
+	boardState := CreateBoard()
+	boardState.ParseFen(StartFen)
+
+	for _, moveStr := range []string{"g1f3", "g8f6", "f3g1"} {
+		boardState.MakeMove(boardState.ParseMove(moveStr))
+		if boardState.IsRepetition() {
+			t.Errorf("Unexpected repetition after %s", moveStr)
+		}
+	}
+	boardState.MakeMove(boardState.ParseMove("f6g8"))
+	if !boardState.IsRepetition() {
+		t.Errorf("Expected a repetition of the start position")
+	}
+}
+
+func TestIsFiftyMoveDraw(t *testing.T) {
+	AllInit()
+	boardState := CreateBoard()
+	boardState.ParseFen(StartFen)
+
+	moves := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	for ply := 0; ply < 100; ply++ {
+		if boardState.IsFiftyMoveDraw() {
+			t.Fatalf("Unexpected fifty move draw after %d half moves", ply)
+		}
+		boardState.MakeMove(boardState.ParseMove(moves[ply%len(moves)]))
+	}
+	if !boardState.IsFiftyMoveDraw() || !boardState.IsDrawn() {
+		t.Errorf("Expected a fifty move draw after 100 half moves")
+	}
+}
+
+func TestRandomLegalMove(t *testing.T) {
+	AllInit()
+	boardState := CreateBoard()
+	// the king is in check by the bishop, only the king moves are legal
+	boardState.ParseFen("4k3/8/8/8/7b/8/3P4/R3K3 w Q - 0 1")
+
+	legal := make(map[int]bool)
+	for _, move := range boardState.GetMoves() {
+		legal[move] = true
+	}
+	for i := 0; i < 100; i++ {
+		var moveList MoveList
+		boardState.GenerateAllMoves(&moveList)
+		if move := boardState.RandomLegalMove(&moveList, rand.Intn); !legal[move] {
+			t.Fatalf("RandomLegalMove returned the illegal move %s", PrintMove(move))
+		}
+	}
+
+	// checkmate: no legal move and the side to move loses
+	boardState.ParseFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	var moveList MoveList
+	boardState.GenerateAllMoves(&moveList)
+	if move := boardState.RandomLegalMove(&moveList, rand.Intn); move != NoMove {
+		t.Errorf("Expected no legal move, got %s", PrintMove(move))
+	}
+	if boardState.HasLegalMove() || boardState.NoMovesResult(White) != Win {
+		t.Errorf("Expected checkmate to be a win for white")
+	}
+}
+
+func TestRootMoves(t *testing.T) {
+	AllInit()
+	boardState := CreateBoard()
+	boardState.ParseFen(StartFen)
+
+	e4, e5 := boardState.ParseMove("e2e4"), boardState.ParseMove("e7e5")
+	if moves := boardState.RootMoves([]int{e4, e5}); len(moves) != 1 || moves[0] != e4 {
+		t.Errorf("Expected only e2e4 to be searched, got %v", moves)
+	}
+	if moves := boardState.RootMoves(nil); len(moves) != 20 {
+		t.Errorf("Expected all 20 moves without searchmoves, got %d", len(moves))
+	}
+	if moves := boardState.RootMoves([]int{e5}); len(moves) != 20 {
+		t.Errorf("Expected all 20 moves when no searchmove is legal, got %d", len(moves))
+	}
+}
+
+func TestMoveToSAN(t *testing.T) {
+	AllInit()
+	tests := []struct {
+		fen, move, san string
+	}{
+		{StartFen, "g1f3", "Nf3"},
+		{StartFen, "e2e4", "e4"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1c1", "O-O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "a1a8", "Rxa8+"},
+		{"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", "d1d8", "Rd8#"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", "b8=Q+"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "b1d2", "Nbd2"},
+		{"4k3/8/8/8/8/1N6/8/1N2K3 w - - 0 1", "b1d2", "N1d2"},
+		{"4k3/8/8/8/Q6Q/8/8/4K2Q w - - 0 1", "h4e4", "Qh4e4+"},
+	}
+
+	for _, test := range tests {
+		boardState := CreateBoard()
+		boardState.ParseFen(test.fen)
+		move := boardState.ParseMove(test.move)
+		if move == NoMove {
+			t.Fatalf("%s: could not parse %s", test.fen, test.move)
+		}
+		if san := boardState.MoveToSAN(move); san != test.san {
+			t.Errorf("%s: expected %s for %s, got %s", test.fen, test.san, test.move, san)
+		}
+	}
+}
@@ -2,12 +2,8 @@ package board
 
 import (
 	"bufio"
-	"fmt"
-	"log"
-	"math/rand"
 	"os"
 	"strings"
-	"time"
 )
 
 // ScanFile reads file and returns []slice with all lines
@@ -16,7 +12,6 @@ func ScanFile(filename string) ([]string, error) {
 
 	f, err := os.OpenFile(filename, os.O_RDONLY, os.ModePerm)
 	if err != nil {
-		log.Printf("open file error: %v", err)
 		return lines, err
 	}
 	defer func() {
@@ -31,22 +26,21 @@ func ScanFile(filename string) ([]string, error) {
 		lines = append(lines, line)
 	}
 	if err := sc.Err(); err != nil {
-		log.Fatalf("scan file error: %v", err)
 		return nil, err
 	}
 
 	return lines, nil
 }
 
-// GetBookMove returns a move from the opening book
-func GetBookMove(pos *ChessBoard) int {
+// GetBookMove returns a move from the opening book. If the book has several moves
+// for the position, intn chooses one of them (i.e. rand.Intn)
+func GetBookMove(pos *ChessBoard, intn func(n int) int) int {
 	if pos.histPly > 25 {
 		return 0
 	}
 
 	book, err := ScanFile(BookFile)
 	if err != nil {
-		fmt.Println("Book error")
 		return 0
 	}
 
@@ -54,8 +48,6 @@ func GetBookMove(pos *ChessBoard) int {
 	for i := 0; i < pos.histPly; i++ {
 		currentLine += PrintMove(pos.history[i].move) + " "
 	}
-
-	fmt.Println(currentLine)
 	bookMoves := make([]int, 0)
 
 	for _, bookLine := range book {
@@ -64,7 +56,6 @@ func GetBookMove(pos *ChessBoard) int {
 			nextMoveStr := RemoveStringToTheRightOfMarker(nextMovesStr, " ")
 
 			if len(nextMoveStr) > 5 {
-				fmt.Println("Book move parsing error")
 				continue // parsing eror
 			} else {
 				bookMoves = append(bookMoves, pos.ParseMove(nextMoveStr))
@@ -74,8 +65,7 @@ func GetBookMove(pos *ChessBoard) int {
 
 	numberOfBookMoves := len(bookMoves)
 	if len(bookMoves) > 0 {
-		rand.Seed(time.Now().Unix())
-		return bookMoves[rand.Intn(numberOfBookMoves)]
+		return bookMoves[intn(numberOfBookMoves)]
 	}
 	return 0
 }
@@ -32,6 +32,11 @@ const (
 	BlackKing
 )
 
+// PieceNum number of piece values (Empty included). The piece lookup tables below are
+// arrays indexed by piece: OffBoard is not a piece, so a square has to be checked for
+// OffBoard before its piece is looked up
+const PieceNum = BlackKing + 1
+
 // Defines for ranks
 const (
 	Rank1 int = iota
@@ -195,7 +200,7 @@ func FileRankToSquare(file, rank int) (square int) {
 }
 
 // PieceKeys hashkeys for each piece for each possible position for the key
-var PieceKeys [13][BoardSquareNum]uint64
+var PieceKeys [PieceNum][BoardSquareNum]uint64
 
 // SideKey the hashkey associated with the current side
 var SideKey uint64
@@ -304,7 +309,8 @@ type SearchInfo struct {
 	StopTime  int
 	TimeSet   bool
 	movesToGo int
-	infinite  bool // if this is true, do not stop search based on time but when the gui sends the stop command
+	Infinite  bool // if this is true, do not stop search based on time but when the gui sends the stop command
+	Ponder    bool // if this is true, search is pondering on the opponent's time until 'ponderhit' or 'stop'
 
 	nodes uint64 // count of all positions that the engine visits in the search tree
 
@@ -313,6 +319,13 @@ type SearchInfo struct {
 
 	GameMode     int  // see consts below
 	PostThinking bool // if true, engine posts its thinking to the gui
+	ShowWDL      bool // if true, the win/draw/loss probabilities are reported with the score
+	Depth        int  // maximum search depth in plies (0 - no limit), only used by the alpha-beta search
+
+	MaxNodes uint64 // maximum number of nodes (alpha-beta) or playouts (UCT) of the search (0 - no limit)
+	Seed     int64  // seed of the random choices of the search (0 - a new seed for every search)
+
+	SearchMoves []int // root moves the search is restricted to (empty - all legal moves), see ChessBoard.RootMoves
 }
 
 // Game Modes
@@ -323,8 +336,8 @@ const (
 	ConsoleMode
 )
 
-// PieceChar map from piece value to string representation
-var PieceChar = map[int]string{
+// PieceChar string representation of each piece
+var PieceChar = [PieceNum]string{
 	Empty:       ".",
 	WhitePawn:   "P",
 	WhiteKnight: "N",
@@ -340,11 +353,17 @@ var PieceChar = map[int]string{
 	BlackKing:   "k",
 }
 
+// MvvLvaRank rank of each piece used for the most valuable victim - least valuable attacker ordering
+var MvvLvaRank = [PieceNum]int{0, 1, 2, 3, 4, 5, 6, 1, 2, 3, 4, 5, 6}
+
+// PieceValue material value of each piece in centipawns
+var PieceValue = [PieceNum]int{0, 100, 325, 325, 550, 1000, 50000, 100, 325, 325, 550, 1000, 50000}
+
 // SideChar string with side characters
 var SideChar = "wb-"
 
-// PieceColour A map used to identify a piece's colour
-var PieceColour = map[int]int{
+// PieceColour colour of each piece (Both for Empty)
+var PieceColour = [PieceNum]int{
 	Empty:       Both,
 	WhitePawn:   White,
 	WhiteKnight: White,
@@ -361,7 +380,7 @@ var PieceColour = map[int]int{
 }
 
 // IsPieceKnight holds information if a given piece is a knight
-var IsPieceKnight = map[int]bool{
+var IsPieceKnight = [PieceNum]bool{
 	Empty:       false,
 	WhitePawn:   false,
 	WhiteKnight: true,
@@ -378,7 +397,7 @@ var IsPieceKnight = map[int]bool{
 }
 
 // IsPieceKing holds information if a given piece is a king
-var IsPieceKing = map[int]bool{
+var IsPieceKing = [PieceNum]bool{
 	Empty:       false,
 	WhitePawn:   false,
 	WhiteKnight: false,
@@ -395,7 +414,7 @@ var IsPieceKing = map[int]bool{
 }
 
 // IsPieceRookQueen holds information if a given piece is a rook or queen
-var IsPieceRookQueen = map[int]bool{
+var IsPieceRookQueen = [PieceNum]bool{
 	Empty:       false,
 	WhitePawn:   false,
 	WhiteKnight: false,
@@ -412,7 +431,7 @@ var IsPieceRookQueen = map[int]bool{
 }
 
 // IsPieceBishopQueen holds information if a given piece is a bishop or queen
-var IsPieceBishopQueen = map[int]bool{
+var IsPieceBishopQueen = [PieceNum]bool{
 	Empty:       false,
 	WhitePawn:   false,
 	WhiteKnight: false,
@@ -429,7 +448,7 @@ var IsPieceBishopQueen = map[int]bool{
 }
 
 // IsPiecePawn holds information if a given piece is a pawn
-var IsPiecePawn = map[int]bool{
+var IsPiecePawn = [PieceNum]bool{
 	Empty:       false,
 	WhitePawn:   true,
 	WhiteKnight: false,
@@ -446,15 +465,15 @@ var IsPiecePawn = map[int]bool{
 }
 
 // PieceDir squares increment for each direction
-var PieceDir = map[int][]int{
-	Empty:       {0, 0, 0, 0, 0, 0, 0},
-	WhitePawn:   {0, 0, 0, 0, 0, 0, 0},
+var PieceDir = [PieceNum][8]int{
+	Empty:       {0, 0, 0, 0, 0, 0, 0, 0},
+	WhitePawn:   {0, 0, 0, 0, 0, 0, 0, 0},
 	WhiteKnight: {-8, -19, -21, -12, 8, 19, 21, 12},
 	WhiteBishop: {-9, -11, 11, 9, 0, 0, 0, 0},
 	WhiteRook:   {-1, -10, 1, 10, 0, 0, 0, 0},
 	WhiteQueen:  {-1, -10, 1, 10, -9, -11, 11, 9},
 	WhiteKing:   {-1, -10, 1, 10, -9, -11, 11, 9},
-	BlackPawn:   {0, 0, 0, 0, 0, 0, 0},
+	BlackPawn:   {0, 0, 0, 0, 0, 0, 0, 0},
 	BlackKnight: {-8, -19, -21, -12, 8, 19, 21, 12},
 	BlackBishop: {-9, -11, 11, 9, 0, 0, 0, 0},
 	BlackRook:   {-1, -10, 1, 10, 0, 0, 0, 0},
@@ -463,7 +482,7 @@ var PieceDir = map[int][]int{
 }
 
 // NumberOfDir number of directions in which each piece can move
-var NumberOfDir = map[int]int{
+var NumberOfDir = [PieceNum]int{
 	Empty:       0,
 	WhitePawn:   0,
 	WhiteKnight: 8,
@@ -50,16 +50,21 @@ func InitSq120To64() {
 	}
 }
 
+// hashKeySeed seed of the hash keys. The keys are the same in every run, so the hash
+// of a position (and everything that depends on it) can be reproduced
+const hashKeySeed = 1070372
+
 // InitHashKeys initializes hashkeys for all pieces and possible positions, for castling rights, for side to move
 func InitHashKeys() {
-	for i := 0; i < 13; i++ {
+	keys := rand.New(rand.NewSource(hashKeySeed))
+	for i := 0; i < PieceNum; i++ {
 		for j := 0; j < BoardSquareNum; j++ {
-			PieceKeys[i][j] = rand.Uint64() // returns a random 64 bit number
+			PieceKeys[i][j] = keys.Uint64() // returns a random 64 bit number
 		}
 	}
 
-	SideKey = rand.Uint64()
+	SideKey = keys.Uint64()
 	for i := 0; i < 16; i++ {
-		CastleKeys[i] = rand.Uint64()
+		CastleKeys[i] = keys.Uint64()
 	}
 }
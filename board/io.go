@@ -2,6 +2,7 @@ package board
 
 import (
 	"fmt"
+	"strings"
 )
 
 // todo rename methods -> they dont print but return a string
@@ -62,3 +63,76 @@ func PrintMoveList(moveList *MoveList) {
 	}
 	fmt.Printf("MoveList Total %d Moves:\n\n", moveList.Count)
 }
+
+// MoveToSAN returns the move in standard algebraic notation (i.e. Nbd7, exd5, O-O, e8=Q+).
+// The move has to be legal in the position
+func (pos *ChessBoard) MoveToSAN(move int) string {
+	from, to := FromSq(move), ToSq(move)
+	piece := pos.Pieces[from]
+	capture := Captured(move) != Empty || move&MoveFlagEnPass != 0
+
+	san := ""
+	switch {
+	case move&MoveFlagCastle != 0:
+		san = "O-O-O"
+		if FilesBoard[to] == FileG {
+			san = "O-O"
+		}
+	case IsPiecePawn[piece]:
+		if capture {
+			san = string(rune('a'+FilesBoard[from])) + "x"
+		}
+		san += PrintSquare(to)
+		if promoted := Promoted(move); promoted != Empty {
+			san += "=" + strings.ToUpper(PieceChar[promoted])
+		}
+	default:
+		san = strings.ToUpper(PieceChar[piece]) + pos.sanDisambiguation(move)
+		if capture {
+			san += "x"
+		}
+		san += PrintSquare(to)
+	}
+
+	pos.MakeMove(move)
+	if pos.InCheck() {
+		if pos.HasLegalMove() {
+			san += "+"
+		} else {
+			san += "#"
+		}
+	}
+	pos.TakeMove()
+	return san
+}
+
+// sanDisambiguation returns the file and/or rank of the moving piece if another piece
+// of the same kind can move to the same square
+func (pos *ChessBoard) sanDisambiguation(move int) string {
+	from, to := FromSq(move), ToSq(move)
+	piece := pos.Pieces[from]
+	ambiguous, sameFile, sameRank := false, false, false
+
+	var moveList MoveList
+	pos.GenerateAllMoves(&moveList)
+	for i := 0; i < moveList.Count; i++ {
+		other := moveList.Moves[i]
+		otherFrom := FromSq(other)
+		if ToSq(other) != to || otherFrom == from || pos.Pieces[otherFrom] != piece || !pos.IsMoveLegal(other) {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || FilesBoard[otherFrom] == FilesBoard[from]
+		sameRank = sameRank || RanksBoard[otherFrom] == RanksBoard[from]
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(rune('a' + FilesBoard[from]))
+	case !sameRank:
+		return string(rune('1' + RanksBoard[from]))
+	}
+	return PrintSquare(from)
+}
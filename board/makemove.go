@@ -66,21 +66,42 @@ func (pos *ChessBoard) movePiece(from, to int) {
 
 // GetMoves returns a list of legal moves for the current position
 func (pos *ChessBoard) GetMoves() []int {
+	return pos.AppendMoves(make([]int, 0, MaxPositionMoves))
+}
+
+// RootMoves returns the legal moves of the position that are to be searched: the
+// legal ones of searchMoves (see SearchInfo.SearchMoves), or all legal moves if
+// searchMoves is empty or none of them is legal
+func (pos *ChessBoard) RootMoves(searchMoves []int) []int {
+	moves := pos.GetMoves()
+	rootMoves := make([]int, 0, len(searchMoves))
+	for _, move := range moves {
+		for _, searchMove := range searchMoves {
+			if move == searchMove {
+				rootMoves = append(rootMoves, move)
+				break
+			}
+		}
+	}
+	if len(rootMoves) == 0 {
+		return moves
+	}
+	return rootMoves
+}
+
+// AppendMoves appends all legal moves of the position to moves and returns the extended
+// slice. Passing a slice with enough capacity avoids any allocation
+func (pos *ChessBoard) AppendMoves(moves []int) []int {
 	var moveList MoveList
 	pos.GenerateAllMoves(&moveList)
 
-	legalMoveList := make([]int, 0, len(moveList.Moves))
-
-	var move int
 	for i := 0; i < moveList.Count; i++ {
-		move = moveList.Moves[i]
+		move := moveList.Moves[i]
 		if pos.IsMoveLegal(move) {
-			// todo this might be too slow -> have predefined size
-			legalMoveList = append(legalMoveList, move)
+			moves = append(moves, move)
 		}
 	}
-
-	return legalMoveList
+	return moves
 }
 
 // IsMoveLegal determines if a move is legal by making the move and check if the king is in check
@@ -364,3 +385,35 @@ func (pos *ChessBoard) TakeMove() {
 		}
 	}
 }
+
+// MakeNullMove passes the turn to the other side without moving a piece (used by null move pruning)
+func (pos *ChessBoard) MakeNullMove() {
+	pos.history[pos.histPly].posKey = pos.posKey
+	pos.history[pos.histPly].move = NoMove
+	pos.history[pos.histPly].fiftyMove = pos.fiftyMove
+	pos.history[pos.histPly].enPas = pos.enPas
+	pos.history[pos.histPly].castlePerm = pos.castlePerm
+
+	if pos.enPas != NoSquare {
+		pos.hashEnPass()
+	}
+	pos.enPas = NoSquare
+
+	pos.histPly++
+	pos.PlayerJustMoved ^= 1
+	pos.Side ^= 1
+	pos.hashSide()
+}
+
+// TakeNullMove reverts a null move, opposite to MakeNullMove()
+func (pos *ChessBoard) TakeNullMove() {
+	pos.histPly--
+
+	pos.castlePerm = pos.history[pos.histPly].castlePerm
+	pos.fiftyMove = pos.history[pos.histPly].fiftyMove
+	pos.enPas = pos.history[pos.histPly].enPas
+	pos.posKey = pos.history[pos.histPly].posKey
+
+	pos.PlayerJustMoved ^= 1
+	pos.Side ^= 1
+}
@@ -117,29 +117,44 @@ func (pos *ChessBoard) generateCastlingMoves(moveList *MoveList) {
 	}
 }
 
+// addPawnMove adds a quiet pawn move of the side to move. The handlers are called
+// directly (not through a func value) so that the move list can stay on the stack
+func (pos *ChessBoard) addPawnMove(from, to int, moveList *MoveList) {
+	if pos.Side == White {
+		pos.addWhitePawnMove(from, to, moveList)
+	} else {
+		pos.addBlackPawnMove(from, to, moveList)
+	}
+}
+
+// addPawnCaptureMove adds a pawn capture of the side to move
+func (pos *ChessBoard) addPawnCaptureMove(from, to, cap int, moveList *MoveList) {
+	if pos.Side == White {
+		pos.addWhitePawnCaptureMove(from, to, cap, moveList)
+	} else {
+		pos.addBlackPawnCaptureMove(from, to, cap, moveList)
+	}
+}
+
 func (pos *ChessBoard) generatePawnMoves(sq int, moveList *MoveList) {
 	var forwardOneSq, forwardTwoSq, captureLeftSq, captureRightSq int
 	var enemy int
 	var pawnRank int
-	var pawnMoveHandler func(from, to int, ml *MoveList)
-	var pawnCaptureMoveHandler func(from, to, cap int, ml *MoveList)
 
 	if pos.Side == White {
 		enemy = Black
 		pawnRank = Rank2
 
 		forwardOneSq, forwardTwoSq, captureLeftSq, captureRightSq = 10, 20, 9, 11
-		pawnMoveHandler, pawnCaptureMoveHandler = pos.addWhitePawnMove, pos.addWhitePawnCaptureMove
 	} else {
 		enemy = White
 		pawnRank = Rank7
 		forwardOneSq, forwardTwoSq, captureLeftSq, captureRightSq = -10, -20, -9, -11
-		pawnMoveHandler, pawnCaptureMoveHandler = pos.addBlackPawnMove, pos.addBlackPawnCaptureMove
 	}
 
 	// add simple pawn move forward if next sq is empty
 	if pos.Pieces[sq+forwardOneSq] == Empty {
-		pawnMoveHandler(sq, sq+forwardOneSq, moveList)
+		pos.addPawnMove(sq, sq+forwardOneSq, moveList)
 		// if we are on the second rank, generate a double pawn move if 4th rank sq is empty
 		if RanksBoard[sq] == pawnRank && pos.Pieces[sq+forwardTwoSq] == Empty {
 			// don't forget to set the flag for PAWN START
@@ -150,12 +165,12 @@ func (pos *ChessBoard) generatePawnMoves(sq int, moveList *MoveList) {
 	// Capture to the left and right
 	// check if the square that we are capturing on is on the board and that it has a black piece on it
 	if pos.isSquareOnBoard(sq+captureLeftSq) && PieceColour[pos.Pieces[sq+captureLeftSq]] == enemy {
-		pawnCaptureMoveHandler(sq, sq+captureLeftSq, pos.Pieces[sq+captureLeftSq], moveList)
+		pos.addPawnCaptureMove(sq, sq+captureLeftSq, pos.Pieces[sq+captureLeftSq], moveList)
 	}
 
 	// check if the square that we are capturing on is on the board and that it has a black piece on it
 	if pos.isSquareOnBoard(sq+captureRightSq) && PieceColour[pos.Pieces[sq+captureRightSq]] == enemy {
-		pawnCaptureMoveHandler(sq, sq+captureRightSq, pos.Pieces[sq+captureRightSq], moveList)
+		pos.addPawnCaptureMove(sq, sq+captureRightSq, pos.Pieces[sq+captureRightSq], moveList)
 	}
 
 	if pos.enPas != NoSquare {
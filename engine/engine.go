@@ -0,0 +1,487 @@
+// Package engine offers the searches of slinky as a library. An Engine searches a
+// position within the given limits and reports its progress through a callback, it
+// never writes to stdout. The UCI and console front ends are adapters over it
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slinky/ab"
+	"slinky/board"
+	"slinky/mate"
+	"slinky/uct"
+	"sync"
+	"time"
+)
+
+// Names of the search algorithms an Engine can use
+const (
+	MCTS      = "mcts"
+	AlphaBeta = "ab"
+)
+
+// Names lists the available search algorithms
+var Names = []string{MCTS, AlphaBeta}
+
+// Defaults of the engine settings
+const (
+	DefaultProgressInterval = time.Second           // time between two progress reports of the UCT search
+	DefaultMoveOverhead     = 30 * time.Millisecond // time lost per move outside of the search
+)
+
+// mateFallbackTime time of the normal search after a mate search without a time limit found no mate
+const mateFallbackTime = 100 * time.Millisecond
+
+// Errors returned by Search
+var (
+	ErrNoPosition       = errors.New("engine: no position to search")
+	ErrGameOver         = errors.New("engine: the game is already over")
+	ErrUnknownAlgorithm = errors.New("engine: unknown search algorithm")
+)
+
+// Limits restrict a search. A search without limits runs until its context is cancelled
+type Limits struct {
+	MoveTime  time.Duration // fixed time for the search (0 - no limit), takes precedence over the clock
+	Time      time.Duration // time left on the clock of the side to move (0 - no clock)
+	Increment time.Duration // increment per move of the side to move
+	MovesToGo int           // moves until the next time control (0 - the rest of the game)
+	Depth     int           // maximum depth in plies, only used by the alpha-beta search (0 - no limit)
+	Nodes     uint64        // maximum number of nodes (alpha-beta) or playouts (UCT) (0 - no limit)
+
+	SearchMoves []int // root moves the search is restricted to (empty - all legal moves)
+	Mate        int   // look only for a mate in at most this many moves (see searchMate), 0 - normal search
+
+	// PonderHit is set when pondering: the time limits only apply once it is closed
+	PonderHit <-chan struct{}
+}
+
+// Line is one of the best root moves with its statistics and principal variation
+type Line struct {
+	Move   int
+	Score  int     // score in centipawns from the point of view of the side to move
+	Mate   int     // moves until mate (negative if the side to move gets mated), 0 if no mate was found
+	WDL    [3]int  // win, draw and loss probability in permille
+	Wins   float64 // UCT only: wins of the side to move (a draw counts as half a win)
+	Visits float64 // UCT only: number of playouts through the move
+	PV     []int
+}
+
+// Info is a snapshot of a (possibly still running) search
+type Info struct {
+	Depth     int           // iteration depth (alpha-beta) or mean tree depth (UCT)
+	SelDepth  int           // deepest ply reached
+	Nodes     uint64        // nodes (alpha-beta) or playouts (UCT) searched so far
+	Time      time.Duration // time since the search started
+	HashFull  int           // usage of the transposition table in permille
+	Lines     []Line        // best root moves (best first)
+	Selection string        // UCT only: move selection policy used to order the root moves
+	Margin    float64       // UCT only: how much better the best move is than the runner-up
+}
+
+// NPS returns the number of nodes searched per second
+func (i Info) NPS() uint64 {
+	if i.Time < time.Millisecond {
+		return 0
+	}
+	return i.Nodes * 1000 / uint64(i.Time.Milliseconds())
+}
+
+// Result is the outcome of a search
+type Result struct {
+	Move       int
+	PonderMove int    // expected reply to the best move (board.NoMove if unknown)
+	Score      int    // score in centipawns from the point of view of the side to move
+	Mate       int    // moves until mate (negative if the side to move gets mated), 0 if no mate was found
+	WDL        [3]int // win, draw and loss probability in permille
+	Book       bool   // the move was taken from the opening book, nothing was searched
+	Info       Info   // final state of the search
+	Stats      Stats  // statistics of the search
+}
+
+// Stats are the statistics of a search. The tree statistics are only collected by the
+// UCT search, they are zero for the alpha-beta search and for book moves
+type Stats struct {
+	uct.SearchStats
+	BookHits int // moves played from the opening book since the engine was created or cleared
+}
+
+// Engine searches positions with the selected algorithm. The search trees and the
+// transposition tables are kept between searches, Clear discards them
+type Engine struct {
+	Algorithm        string        // search algorithm (see Names)
+	MultiPV          int           // number of best root moves reported
+	OwnBook          bool          // play moves from the opening book if possible
+	OnProgress       func(Info)    // called with the progress of the search (nil - no reports)
+	ProgressInterval time.Duration // time between two progress reports of the UCT search
+	MoveOverhead     time.Duration // time lost per move outside of the search (i.e. by the GUI)
+	Seed             int64         // seed of the random choices (0 - random), see uct.Searcher.Search
+	LimitStrength    bool          // play at the rating Elo (see Strength)
+	Elo              int           // rating of the limited strength
+	Temperature      float64       // temperature of the root move sampling of the UCT search (0 - play the best move)
+
+	UCT *uct.Searcher
+	AB  *ab.Searcher
+
+	bookHits    int
+	stats       Stats       // statistics of the last search
+	personality Personality // base of the style (see SetPersonality)
+	style       styleOptions
+}
+
+// New creates an engine using the UCT search
+func New() *Engine {
+	return &Engine{
+		Algorithm:        MCTS,
+		MultiPV:          1,
+		OwnBook:          true,
+		ProgressInterval: DefaultProgressInterval,
+		MoveOverhead:     DefaultMoveOverhead,
+		Elo:              DefaultElo,
+		UCT:              uct.NewSearcher(),
+		AB:               ab.NewSearcher(),
+		personality:      Personalities[0],
+	}
+}
+
+// Clear discards what the engine learned about the previous game
+func (e *Engine) Clear() {
+	e.UCT.Clear()
+	e.AB.Clear()
+	e.bookHits = 0
+	e.stats = Stats{}
+}
+
+// Stats returns the statistics of the last search
+func (e *Engine) Stats() Stats {
+	return e.stats
+}
+
+// Search searches the position until ctx is cancelled or a limit is reached and
+// returns the best move. With a limited strength or a temperature the move played
+// may be another one (see weaken). Only the legal moves of limits.SearchMoves are
+// searched if it has any. The position is not modified
+func (e *Engine) Search(ctx context.Context, pos *board.ChessBoard, limits Limits) (Result, error) {
+	if pos == nil {
+		return Result{}, ErrNoPosition
+	}
+	if e.Algorithm != MCTS && e.Algorithm != AlphaBeta {
+		return Result{}, ErrUnknownAlgorithm
+	}
+	searchPos := *pos
+	if len(searchPos.GetMoves()) == 0 {
+		return Result{}, ErrGameOver
+	}
+
+	if limits.Mate > 0 {
+		result := e.searchMate(ctx, &searchPos, limits)
+		e.stats = Stats{BookHits: e.bookHits}
+		result.Stats = e.stats
+		return result, nil
+	}
+
+	rng := e.random()
+	if e.OwnBook && len(limits.SearchMoves) == 0 {
+		if move := board.GetBookMove(&searchPos, rng.Intn); move != board.NoMove {
+			e.bookHits++
+			e.stats = Stats{BookHits: e.bookHits}
+			return Result{Move: move, PonderMove: board.NoMove, WDL: [3]int{0, 1000, 0}, Book: true, Stats: e.stats}, nil
+		}
+	}
+
+	strength := e.strength()
+	e.limitNodes(&limits, strength)
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	info := &board.SearchInfo{StartTime: time.Now(), Depth: limits.Depth, MaxNodes: limits.Nodes, Seed: e.Seed,
+		SearchMoves: limits.SearchMoves}
+	var tm *timeManager
+	if limits.MoveTime > 0 && limits.PonderHit == nil {
+		// a fixed time per move is kept by the searches themselves
+		info.TimeSet = true
+		info.StopTime = int(moveTime(limits, e.MoveOverhead).Milliseconds())
+	} else if limits.MoveTime > 0 || limits.Time > 0 {
+		tm = newTimeManager(limits, e.MoveOverhead, &searchPos)
+		if e.Algorithm == MCTS && e.UCT.Config.MoveSelection == uct.MaxRobustChildName {
+			tm.waitForAgreement(e.UCT.MaxRobustAgree, uct.MaxRobustExtension)
+		}
+	}
+
+	control := timeControl{tm: tm, stop: stop, ponderHit: limits.PonderHit}
+	var result Result
+	if e.Algorithm == AlphaBeta {
+		result = e.searchAlphaBeta(ctx, &searchPos, info, control)
+	} else {
+		result = e.searchUCT(ctx, &searchPos, info, control)
+	}
+	result = e.weaken(result, searchPos.RootMoves(limits.SearchMoves), strength, rng)
+	result.Stats.BookHits = e.bookHits
+	e.stats = result.Stats
+	return result, nil
+}
+
+// random returns the random number generator of a search: seeded with the seed of the
+// engine if it has one, otherwise with the current time
+func (e *Engine) random() *rand.Rand {
+	seed := e.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// report calls the progress callback if there is one
+func (e *Engine) report(info Info) {
+	if e.OnProgress != nil {
+		e.OnProgress(info)
+	}
+}
+
+// searchAlphaBeta runs the alpha-beta search, every completed iteration is reported
+func (e *Engine) searchAlphaBeta(ctx context.Context, pos *board.ChessBoard, searchInfo *board.SearchInfo, control timeControl) Result {
+	var info Info
+	var mu sync.Mutex
+	bestMove := board.NoMove
+	report := func(result ab.Result) {
+		info = alphaBetaInfo(result, time.Since(searchInfo.StartTime))
+		mu.Lock()
+		bestMove = result.Move
+		mu.Unlock()
+		e.report(info)
+	}
+	// the alpha-beta search does not know how its moves compare, only the changes of the best move count
+	finishTimeControl := control.start(ctx, func() (int, float64, float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		return bestMove, -1, -1
+	})
+
+	result := e.AB.Search(ctx, pos, searchInfo, report)
+	finishTimeControl()
+	info.Time = time.Since(searchInfo.StartTime)
+	info.Nodes = result.Nodes
+
+	return Result{
+		Move:       result.Move,
+		PonderMove: result.PonderMove,
+		Score:      result.Score,
+		Mate:       result.Mate,
+		WDL:        ab.WDL(result.Score),
+		Info:       info,
+	}
+}
+
+// alphaBetaInfo converts a completed iteration of the alpha-beta search
+func alphaBetaInfo(result ab.Result, elapsed time.Duration) Info {
+	line := Line{Move: result.Move, Score: result.Score, Mate: result.Mate, WDL: result.WDL, PV: result.PV}
+	return Info{
+		Depth:    result.Depth,
+		SelDepth: result.SelDepth,
+		Nodes:    result.Nodes,
+		Time:     elapsed,
+		HashFull: result.HashFull,
+		Lines:    []Line{line},
+	}
+}
+
+// searchUCT runs the UCT search, its progress is reported at a fixed interval and once
+// more when the search has finished
+func (e *Engine) searchUCT(ctx context.Context, pos *board.ChessBoard, searchInfo *board.SearchInfo, control timeControl) Result {
+	finishTimeControl := control.start(ctx, e.uctBestMove)
+	searchDone := make(chan struct{})
+	reporterDone := make(chan struct{})
+	go func() {
+		defer close(reporterDone)
+		if e.OnProgress == nil || e.ProgressInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(e.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-searchDone:
+				return
+			case <-ticker.C:
+				e.report(e.uctInfo(time.Since(searchInfo.StartTime)))
+			}
+		}
+	}()
+
+	result := e.UCT.Search(ctx, pos, searchInfo)
+	close(searchDone)
+	<-reporterDone
+	finishTimeControl()
+
+	final := Result{
+		Move:       result.Move,
+		PonderMove: result.PonderMove,
+		Score:      uct.Centipawns(result.Score, e.UCT.Config.EvalScale),
+		WDL:        [3]int{0, 1000, 0},
+		Info:       Info{Time: time.Since(searchInfo.StartTime)},
+	}
+	// a single legal move is played without searching, the trees hold the previous search
+	if result.Simulations == 0 {
+		return final
+	}
+
+	final.Stats.SearchStats = e.UCT.Stats()
+	final.Info = e.uctInfo(final.Info.Time)
+	for _, line := range final.Info.Lines {
+		if line.Move == final.Move {
+			final.Mate, final.WDL = line.Mate, line.WDL
+		}
+	}
+	e.report(final.Info)
+	return final
+}
+
+// uctInfo takes a snapshot of the UCT search
+func (e *Engine) uctInfo(elapsed time.Duration) Info {
+	progress := e.UCT.Progress(e.MultiPV)
+	info := Info{
+		Depth:     progress.Depth,
+		SelDepth:  progress.SelDepth,
+		Nodes:     uint64(progress.Simulations),
+		Time:      elapsed,
+		HashFull:  progress.HashFull,
+		Selection: progress.Selection,
+		Margin:    progress.Margin,
+	}
+	for _, line := range progress.Lines {
+		info.Lines = append(info.Lines, Line{
+			Move:   line.Move,
+			Score:  line.Cp,
+			Mate:   line.Mate,
+			WDL:    line.WDL,
+			Wins:   line.Wins,
+			Visits: line.Visits,
+			PV:     line.PV,
+		})
+	}
+	return info
+}
+
+// uctBestMove returns the most visited root move, its visit share and its visit gap to
+// the runner-up (relative to all visits) for the time management
+func (e *Engine) uctBestMove() (int, float64, float64) {
+	best, second, total := uct.RootMove{Move: board.NoMove}, 0.0, 0.0
+	for _, rootMove := range e.UCT.RootMoves() {
+		total += rootMove.Visits
+		if rootMove.Visits > best.Visits {
+			second = best.Visits
+			best = rootMove
+		} else if rootMove.Visits > second {
+			second = rootMove.Visits
+		}
+	}
+	if total == 0 {
+		return best.Move, -1, -1
+	}
+	return best.Move, best.Visits / total, (best.Visits - second) / total
+}
+
+// String formats the statistics as a report with one value per line
+func (s *Stats) String() string {
+	phases := s.Selection + s.Expansion + s.Rollout + s.Backup
+	share := func(d time.Duration) float64 {
+		if phases == 0 {
+			return 0
+		}
+		return 100 * float64(d) / float64(phases)
+	}
+
+	line := fmt.Sprintf("%-22s %d\n", "Book hits", s.BookHits)
+	line += fmt.Sprintf("%-22s %d\n", "Tree nodes", s.Nodes)
+	line += fmt.Sprintf("%-22s %d / %.1f\n", "Depth (max / mean)", s.MaxDepth, s.AverageDepth)
+	line += fmt.Sprintf("%-22s %.2f\n", "Branching factor", s.Branching)
+	line += fmt.Sprintf("%-22s %d in %v\n", "Playouts", s.Playouts, s.Elapsed.Round(time.Millisecond))
+	line += fmt.Sprintf("%-22s %.0f/s (%.0f/s x %d workers)\n", "Playout rate", s.Rate, s.WorkerRate, s.Workers)
+	line += fmt.Sprintf("%-22s %.1f plies\n", "Rollout length", s.RolloutPlies)
+	line += fmt.Sprintf("%-22s mate %.1f%% draw %.1f%% cutoff %.1f%%\n", "Rollout results",
+		100*s.MateShare, 100*s.DrawShare, 100*s.CutoffShare)
+	line += fmt.Sprintf("%-22s selection %.1f%% expansion %.1f%% rollout %.1f%% backup %.1f%%\n", "Time",
+		share(s.Selection), share(s.Expansion), share(s.Rollout), share(s.Backup))
+	return line
+}
+
+// searchMate runs the mate search of limits.Mate moves (see mate.Search) over the root
+// moves. With a move time or a clock it stops at the hard budget of the time manager,
+// otherwise only when the distance is searched completely or ctx is cancelled. If no
+// mate is found, Mate is 0 and the move is chosen by a short normal search (see
+// mateFallback)
+func (e *Engine) searchMate(ctx context.Context, pos *board.ChessBoard, limits Limits) Result {
+	start := time.Now()
+	mateCtx, deadline := ctx, time.Time{}
+	if limits.MoveTime > 0 || limits.Time > 0 {
+		// the mate search gets the hard budget, a mate is only of use once it is proven
+		var cancel context.CancelFunc
+		deadline = start.Add(newTimeManager(limits, e.MoveOverhead, pos).hard)
+		mateCtx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	rootMoves := pos.RootMoves(limits.SearchMoves)
+	report := func(result mate.Result) { e.report(mateInfo(result, time.Since(start))) }
+	found := mate.Search(mateCtx, pos, limits.Mate, rootMoves, report)
+
+	result := Result{
+		PonderMove: board.NoMove,
+		WDL:        [3]int{0, 1000, 0},
+		Info:       mateInfo(found, time.Since(start)),
+	}
+	if found.Mate == 0 {
+		return e.mateFallback(ctx, pos, limits, deadline, result)
+	}
+	line := result.Info.Lines[0]
+	result.Move, result.Score, result.Mate, result.WDL = line.Move, line.Score, line.Mate, line.WDL
+	if len(line.PV) > 1 {
+		result.PonderMove = line.PV[1]
+	}
+	return result
+}
+
+// mateFallback chooses the move of a mate search that found no mate with a normal
+// search over the same root moves. It searches until the deadline of the mate search
+// (at least minBudget from now), or for mateFallbackTime if the mate search had none.
+// The book and the strength limit are not used, the result keeps the information of
+// the mate search
+func (e *Engine) mateFallback(ctx context.Context, pos *board.ChessBoard, limits Limits, deadline time.Time, result Result) Result {
+	if deadline.IsZero() {
+		deadline = time.Now().Add(mateFallbackTime)
+	} else if earliest := time.Now().Add(minBudget); deadline.Before(earliest) {
+		deadline = earliest
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	info := &board.SearchInfo{StartTime: time.Now(), Seed: e.Seed, SearchMoves: limits.SearchMoves}
+	var fallback Result
+	if e.Algorithm == AlphaBeta {
+		fallback = e.searchAlphaBeta(ctx, pos, info, timeControl{})
+	} else {
+		fallback = e.searchUCT(ctx, pos, info, timeControl{})
+	}
+	result.Move, result.PonderMove, result.Score, result.WDL = fallback.Move, fallback.PonderMove, fallback.Score, fallback.WDL
+	return result
+}
+
+// mateInfo converts the result of the mate search. The depth is the number of plies
+// searched completely, a found mate is the only line
+func mateInfo(result mate.Result, elapsed time.Duration) Info {
+	info := Info{Depth: 2*result.Depth - 1, SelDepth: 2*result.Depth - 1, Nodes: result.Nodes, Time: elapsed}
+	if result.Depth == 0 {
+		info.Depth, info.SelDepth = 0, 0
+	}
+	if result.Mate > 0 {
+		info.Lines = []Line{{
+			Move:  result.Move,
+			Score: ab.MateScore - (2*result.Mate - 1),
+			Mate:  result.Mate,
+			WDL:   [3]int{1000, 0, 0},
+			PV:    result.PV,
+		}}
+	}
+	return info
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"slinky/board"
+	"testing"
+	"time"
+)
+
+// TestSearch checks that both algorithms find a mate in one and report their progress
+func TestSearch(t *testing.T) {
+	board.AllInit()
+
+	for _, algorithm := range Names {
+		pos := board.CreateBoard()
+		pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+		fen := pos.GenerateFen()
+
+		e := New()
+		e.Algorithm = algorithm
+		reports := 0
+		e.OnProgress = func(info Info) { reports++ }
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		result, err := e.Search(ctx, &pos, Limits{Depth: 4})
+		cancel()
+
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", algorithm, err)
+		}
+		if board.PrintMove(result.Move) != "d1d8" || result.Mate != 1 {
+			t.Errorf("%s: expected mate in 1 with d1d8, got %s (mate %d)", algorithm, board.PrintMove(result.Move), result.Mate)
+		}
+		if result.WDL != [3]int{1000, 0, 0} {
+			t.Errorf("%s: expected a certain win, got %v", algorithm, result.WDL)
+		}
+		if reports == 0 || len(result.Info.Lines) == 0 {
+			t.Errorf("%s: expected progress reports, got %d reports and %d lines", algorithm, reports, len(result.Info.Lines))
+		}
+		if pos.GenerateFen() != fen {
+			t.Errorf("%s: the position was modified", algorithm)
+		}
+	}
+}
+
+// TestSearchGameOver checks that a finished game is reported as an error
+func TestSearchGameOver(t *testing.T) {
+	board.AllInit()
+
+	pos := board.CreateBoard()
+	pos.ParseFen("3R2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 1 1")
+	if _, err := New().Search(context.Background(), &pos, Limits{}); err != ErrGameOver {
+		t.Errorf("expected ErrGameOver, got %v", err)
+	}
+	if _, err := New().Search(context.Background(), nil, Limits{}); err != ErrNoPosition {
+		t.Errorf("expected ErrNoPosition, got %v", err)
+	}
+}
+
+// TestSearchBookStats checks that the book moves are counted
+func TestSearchBookStats(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	// the book is read relative to the root of the repository
+	wd, _ := os.Getwd()
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	e := New()
+	result, err := e.Search(context.Background(), &pos, Limits{Nodes: 10})
+	if err != nil || !result.Book {
+		t.Fatalf("expected a book move for the initial position, got %+v (%v)", result, err)
+	}
+	if result.Stats.BookHits != 1 || e.Stats().BookHits != 1 {
+		t.Errorf("expected 1 book hit, got %d", result.Stats.BookHits)
+	}
+	e.Clear()
+	if e.Stats().BookHits != 0 {
+		t.Errorf("expected Clear to reset the book hits")
+	}
+}
+
+// TestSearchMate checks that a mate search reports the mate and its line, or no mate
+// if the distance is too short
+func TestSearchMate(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("7k/8/5K2/8/8/8/8/6R1 w - - 0 1")
+
+	result, err := New().Search(context.Background(), &pos, Limits{Mate: 3})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if board.PrintMove(result.Move) != "f6f7" || result.Mate != 2 || len(result.Info.Lines) != 1 {
+		t.Errorf("expected mate in 2 with f6f7, got mate %d with %s", result.Mate, board.PrintMove(result.Move))
+	} else if pv := result.Info.Lines[0].PV; len(pv) != 3 || result.PonderMove != pv[1] {
+		t.Errorf("expected a mating line of 3 plies starting with the ponder move, got %v", pv)
+	}
+
+	result, _ = New().Search(context.Background(), &pos, Limits{Mate: 1})
+	if result.Mate != 0 || result.Move == board.NoMove || len(result.Info.Lines) != 0 || result.Info.Depth != 1 {
+		t.Errorf("expected no mate in 1 after searching 1 ply, got %+v", result)
+	}
+
+	// without a mate the move comes from a normal search, which takes the hanging rook
+	pos.ParseFen("6k1/8/8/3r4/8/8/5PPP/3Q2K1 w - - 0 1")
+	e := New()
+	e.Algorithm = AlphaBeta
+	if result, _ := e.Search(context.Background(), &pos, Limits{Mate: 1}); board.PrintMove(result.Move) != "d1d5" || result.Mate != 0 {
+		t.Errorf("expected the fallback search to take the rook without a mate, got %s (mate %d)", board.PrintMove(result.Move), result.Mate)
+	}
+
+	// the fallback search ends with the move time even if the mate search used all of it
+	pos.ParseFen(board.StartFen)
+	for _, algorithm := range Names {
+		e := New()
+		e.Algorithm = algorithm
+		e.MoveOverhead = 0
+		start := time.Now()
+		result, err := e.Search(context.Background(), &pos, Limits{Mate: 5, MoveTime: 200 * time.Millisecond})
+		if err != nil || result.Move == board.NoMove || result.Mate != 0 {
+			t.Errorf("%s: expected a move without a mate, got %+v (%v)", algorithm, result, err)
+		}
+		if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+			t.Errorf("%s: the search took %v with a move time of 200ms", algorithm, elapsed)
+		}
+	}
+
+	// a long mate search is bounded by the clock
+	pos.ParseFen(board.StartFen)
+	start := time.Now()
+	if _, err := New().Search(context.Background(), &pos, Limits{Mate: 20, Time: time.Second}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the mate search took %v with 1s on the clock", elapsed)
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"slinky/board"
+	"testing"
+)
+
+// goldenSearch is a seeded, node-limited search with its expected outcome. The
+// expected values change whenever the search changes, they have to be updated then
+type goldenSearch struct {
+	fen       string
+	algorithm string
+	nodes     uint64 // node limit of the search
+
+	move       string // expected best move
+	score      int    // expected score of the best move in centipawns
+	nodesSpent uint64 // expected number of nodes searched
+}
+
+var goldenSearches = []goldenSearch{
+	{fen: "r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9", algorithm: MCTS, nodes: 400,
+		move: "a1c1", score: 209, nodesSpent: 400},
+	// the root is proven after a few playouts, every tree stops on its own
+	{fen: "6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", algorithm: MCTS, nodes: 200,
+		move: "d1d8", score: 2000, nodesSpent: 23},
+	{fen: "r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9", algorithm: AlphaBeta, nodes: 20000,
+		move: "f1e2", score: -70, nodesSpent: 20000},
+}
+
+// goldenEngine returns an engine whose searches only depend on the position and the limits
+func goldenEngine(algorithm string) *Engine {
+	e := New()
+	e.Algorithm = algorithm
+	e.OwnBook = false
+	e.Seed = 7
+	e.UCT.Config.Threads = 2
+	e.UCT.Config.RolloutDepth = 8
+	return e
+}
+
+// withoutTimes clears the parts of the result that depend on the speed of the search
+func withoutTimes(result Result) Result {
+	result.Info.Time = 0
+	stats := &result.Stats
+	stats.Elapsed, stats.Rate, stats.WorkerRate = 0, 0, 0
+	stats.Selection, stats.Expansion, stats.Rollout, stats.Backup = 0, 0, 0, 0
+	return result
+}
+
+// TestSearchReproducible checks that a seeded, node-limited search gives the same result
+// every time, also after other searches with the same engine
+func TestSearchReproducible(t *testing.T) {
+	board.AllInit()
+
+	for _, golden := range goldenSearches {
+		pos := board.CreateBoard()
+		pos.ParseFen(golden.fen)
+
+		e := goldenEngine(golden.algorithm)
+		first, err := e.Search(context.Background(), &pos, Limits{Nodes: golden.nodes})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", golden.algorithm, err)
+		}
+		second, _ := e.Search(context.Background(), &pos, Limits{Nodes: golden.nodes})
+		third, _ := goldenEngine(golden.algorithm).Search(context.Background(), &pos, Limits{Nodes: golden.nodes})
+
+		for _, result := range []Result{second, third} {
+			if !reflect.DeepEqual(withoutTimes(first), withoutTimes(result)) {
+				t.Errorf("%s %s: expected the same result, got %+v and %+v", golden.algorithm, golden.fen, first, result)
+			}
+		}
+	}
+}
+
+// TestSearchGolden compares seeded, node-limited searches with their recorded outcome
+func TestSearchGolden(t *testing.T) {
+	board.AllInit()
+
+	for _, golden := range goldenSearches {
+		pos := board.CreateBoard()
+		pos.ParseFen(golden.fen)
+
+		result, err := goldenEngine(golden.algorithm).Search(context.Background(), &pos, Limits{Nodes: golden.nodes})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", golden.algorithm, err)
+		}
+		move := board.PrintMove(result.Move)
+		if move != golden.move || result.Score != golden.score || result.Info.Nodes != golden.nodesSpent {
+			t.Errorf("%s %s: expected %s with score %d after %d nodes, got %s with score %d after %d nodes",
+				golden.algorithm, golden.fen, golden.move, golden.score, golden.nodesSpent, move, result.Score, result.Info.Nodes)
+		}
+	}
+}
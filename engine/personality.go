@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"errors"
+	"slinky/uct"
+)
+
+// ErrUnknownPersonality is returned by SetPersonality for names not in PersonalityNames
+var ErrUnknownPersonality = errors.New("engine: unknown personality")
+
+// Personality is a preset of the playing style of the engine. It is the base layer of
+// the style: the options set explicitly (see SetRolloutPolicy, SetContempt and
+// SetContemptPhase) take precedence over it, whatever the order they were set in
+type Personality struct {
+	Name          string
+	Policy        uct.RolloutPolicy // rollout policy of the UCT search
+	Contempt      int               // contempt of both searches in centipawns (see uct.Config.Contempt)
+	ContemptPhase bool              // scale the contempt with the game phase (see uct.Config.ContemptPhase)
+	Temperature   float64           // temperature of the root move sampling (see Engine.Temperature)
+}
+
+// styleOptions are the style options set explicitly over the personality
+type styleOptions struct {
+	policy           uct.RolloutPolicy // nil if not set
+	contempt         int
+	contemptSet      bool
+	contemptPhase    bool
+	contemptPhaseSet bool
+}
+
+// DefaultPersonality restores the default style
+const DefaultPersonality = "default"
+
+// Personalities lists the presets, the default one first
+var Personalities = []Personality{
+	{Name: DefaultPersonality, Policy: uct.UniformPolicy{}},
+	// rollouts full of checks and promotions, draws are avoided while there are pieces to attack with
+	{Name: "aggressive", Policy: uct.TacticalPolicy{CheckWeight: 24, PromotionWeight: 16}, Contempt: 60, ContemptPhase: true},
+	// rollouts that trade by MVV-LVA and keep the material, draws are welcome
+	{Name: "solid", Policy: uct.CapturePolicy{}, Contempt: -30},
+	// plays any reasonable move in proportion to its visits
+	{Name: "random", Policy: uct.UniformPolicy{}, Temperature: 1},
+}
+
+// PersonalityNames lists the names of the presets
+var PersonalityNames = personalityNames()
+
+func personalityNames() []string {
+	names := make([]string, 0, len(Personalities))
+	for _, p := range Personalities {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// SetPersonality selects the preset with the given name as the base of the style.
+// The style options set explicitly are kept
+func (e *Engine) SetPersonality(name string) error {
+	for _, p := range Personalities {
+		if p.Name == name {
+			e.personality = p
+			e.applyStyle()
+			return nil
+		}
+	}
+	return ErrUnknownPersonality
+}
+
+// SetRolloutPolicy sets the rollout policy of the UCT search over the personality
+func (e *Engine) SetRolloutPolicy(policy uct.RolloutPolicy) {
+	e.style.policy = policy
+	e.applyStyle()
+}
+
+// SetContempt sets the contempt of both searches over the personality
+func (e *Engine) SetContempt(contempt int) {
+	e.style.contempt, e.style.contemptSet = contempt, true
+	e.applyStyle()
+}
+
+// SetContemptPhase sets whether the contempt of both searches is scaled with the game
+// phase over the personality
+func (e *Engine) SetContemptPhase(phased bool) {
+	e.style.contemptPhase, e.style.contemptPhaseSet = phased, true
+	e.applyStyle()
+}
+
+// ResetStyle forgets the style options set explicitly, so that the personality alone
+// decides the style
+func (e *Engine) ResetStyle() {
+	e.style = styleOptions{}
+	e.applyStyle()
+}
+
+// applyStyle configures the searches with the personality overridden by the style
+// options set explicitly
+func (e *Engine) applyStyle() {
+	p := e.personality
+	if e.style.policy != nil {
+		p.Policy = e.style.policy
+	}
+	if e.style.contemptSet {
+		p.Contempt = e.style.contempt
+	}
+	if e.style.contemptPhaseSet {
+		p.ContemptPhase = e.style.contemptPhase
+	}
+
+	e.UCT.Config.Policy = p.Policy
+	e.UCT.Config.Contempt, e.AB.Contempt = p.Contempt, p.Contempt
+	e.UCT.Config.ContemptPhase, e.AB.ContemptPhase = p.ContemptPhase, p.ContemptPhase
+	e.Temperature = p.Temperature
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"slinky/board"
+	"slinky/uct"
+)
+
+// Range of the ratings of a limited strength (see Strength)
+const (
+	MinElo     = 800
+	MaxElo     = 2400
+	DefaultElo = 1500
+)
+
+const (
+	minEloPlayouts    = 16   // playouts of a search at MinElo
+	eloPerDoubling    = 150  // rating points gained by doubling the playouts
+	abNodesPerPlayout = 10   // alpha-beta nodes allowed per playout of the budget
+	maxTemperature    = 1.0  // temperature of the root move sampling at MinElo
+	maxBlunderRate    = 0.15 // probability of a deliberate blunder at MinElo
+)
+
+// StrengthLimit holds the settings that weaken the engine to a rating
+type StrengthLimit struct {
+	Playouts    uint64  // playouts of a search (the alpha-beta search gets abNodesPerPlayout nodes per playout)
+	Temperature float64 // temperature of the root move sampling (see sampleMove)
+	BlunderRate float64 // probability of playing a random move instead of the chosen one
+}
+
+// Strength maps a rating (clamped to MinElo-MaxElo) to the limits of the search.
+// The playouts double every eloPerDoubling points, while the temperature and the
+// blunder probability fall linearly to 0 at MaxElo
+func Strength(elo int) StrengthLimit {
+	if elo < MinElo {
+		elo = MinElo
+	} else if elo > MaxElo {
+		elo = MaxElo
+	}
+	weakness := float64(MaxElo-elo) / float64(MaxElo-MinElo)
+	return StrengthLimit{
+		Playouts:    uint64(minEloPlayouts * math.Pow(2, float64(elo-MinElo)/eloPerDoubling)),
+		Temperature: maxTemperature * weakness,
+		BlunderRate: maxBlunderRate * weakness,
+	}
+}
+
+// strength returns the limits of the next search: those of the rating if the
+// strength is limited and the temperature of the engine otherwise
+func (e *Engine) strength() StrengthLimit {
+	limit := StrengthLimit{}
+	if e.LimitStrength {
+		limit = Strength(e.Elo)
+	}
+	limit.Temperature = math.Max(limit.Temperature, e.Temperature)
+	return limit
+}
+
+// limitNodes lowers the node limit of a search to the budget of the limited strength
+func (e *Engine) limitNodes(limits *Limits, limit StrengthLimit) {
+	budget := limit.Playouts
+	if budget == 0 {
+		return
+	}
+	if e.Algorithm == AlphaBeta {
+		budget *= abNodesPerPlayout
+	}
+	if limits.Nodes == 0 || budget < limits.Nodes {
+		limits.Nodes = budget
+	}
+}
+
+// weaken replaces the best move of a search with a sampled root move or a blunder
+// among moves, the legal root moves of the search. The root moves are only sampled
+// after a UCT search since they need the visits. If the move changes, the reported
+// score is taken from the lines of the search if the move is one of them, otherwise
+// the score of the best move is kept
+func (e *Engine) weaken(result Result, moves []int, limit StrengthLimit, rng *rand.Rand) Result {
+	if result.Book || len(moves) < 2 {
+		return result
+	}
+
+	move := result.Move
+	if limit.Temperature > 0 && e.Algorithm == MCTS && result.Info.Nodes > 0 {
+		move = sampleMove(e.UCT.RootMoves(), limit.Temperature, rng, move)
+	}
+	if limit.BlunderRate > 0 && rng.Float64() < limit.BlunderRate {
+		// any other legal move
+		blunder := rng.Intn(len(moves) - 1)
+		for _, m := range moves {
+			if m == move {
+				continue
+			}
+			if blunder == 0 {
+				move = m
+				break
+			}
+			blunder--
+		}
+	}
+	if move == result.Move {
+		return result
+	}
+
+	result.Move = move
+	result.PonderMove = board.NoMove
+	for _, line := range result.Info.Lines {
+		if line.Move == move {
+			result.Score, result.Mate, result.WDL = line.Score, line.Mate, line.WDL
+		}
+	}
+	return result
+}
+
+// sampleMove picks a root move with a probability proportional to
+// (visits / most visits)^(1/temperature): a low temperature nearly always picks the
+// most visited move, a temperature of 1 picks the moves in proportion to their visits.
+// Proven losses are never picked. best is returned if no root move was visited
+func sampleMove(rootMoves []uct.RootMove, temperature float64, rng *rand.Rand, best int) int {
+	mostVisits := 0.0
+	for _, rootMove := range rootMoves {
+		mostVisits = math.Max(mostVisits, rootMove.Visits)
+	}
+	if mostVisits == 0 {
+		return best
+	}
+
+	weights := make([]float64, len(rootMoves))
+	total := 0.0
+	for i, rootMove := range rootMoves {
+		if rootMove.Proof != uct.ProvenLoss {
+			weights[i] = math.Pow(rootMove.Visits/mostVisits, 1/temperature)
+			total += weights[i]
+		}
+	}
+	if total == 0 {
+		return best
+	}
+
+	r := rng.Float64() * total
+	for i, w := range weights {
+		r -= w
+		if r < 0 && w > 0 {
+			return rootMoves[i].Move
+		}
+	}
+	return best
+}
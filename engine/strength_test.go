@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"math/rand"
+	"slinky/board"
+	"slinky/uct"
+	"testing"
+)
+
+// TestStrength checks that a higher rating searches more and makes fewer random choices
+func TestStrength(t *testing.T) {
+	last := Strength(MinElo - 100)
+	if last != Strength(MinElo) {
+		t.Errorf("expected ratings below MinElo to be clamped, got %+v", last)
+	}
+	for elo := MinElo + 100; elo <= MaxElo; elo += 100 {
+		limit := Strength(elo)
+		if limit.Playouts <= last.Playouts || limit.Temperature >= last.Temperature || limit.BlunderRate >= last.BlunderRate {
+			t.Errorf("limits of %d (%+v) are not stronger than the ones below (%+v)", elo, limit, last)
+		}
+		last = limit
+	}
+	if last.Temperature != 0 || last.BlunderRate != 0 {
+		t.Errorf("expected no random choices at MaxElo, got %+v", last)
+	}
+}
+
+// TestSampleMove checks that a low temperature picks the most visited move and that
+// proven losses are never picked
+func TestSampleMove(t *testing.T) {
+	rootMoves := []uct.RootMove{
+		{Move: 1, Visits: 100},
+		{Move: 2, Visits: 60},
+		{Move: 3, Visits: 200, Proof: uct.ProvenLoss},
+	}
+	rng := rand.New(rand.NewSource(1))
+	picked := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		picked[sampleMove(rootMoves, 1, rng, 1)]++
+		if move := sampleMove(rootMoves, 0.01, rng, 1); move != 1 {
+			t.Fatalf("expected the most visited move at a low temperature, got %d", move)
+		}
+	}
+	if picked[3] != 0 || picked[2] < 250 || picked[1] < picked[2] {
+		t.Errorf("expected moves 1 and 2 in proportion to their visits, got %v", picked)
+	}
+}
+
+// TestSearchLimitStrength checks that the playouts are limited by the rating
+func TestSearchLimitStrength(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	e := goldenEngine(MCTS)
+	e.LimitStrength = true
+	e.Elo = MinElo
+	result, err := e.Search(context.Background(), &pos, Limits{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if budget := Strength(MinElo).Playouts; result.Info.Nodes != budget {
+		t.Errorf("expected %d playouts, got %d", budget, result.Info.Nodes)
+	}
+	legal := false
+	for _, move := range pos.GetMoves() {
+		legal = legal || move == result.Move
+	}
+	if !legal {
+		t.Errorf("illegal move %s", board.PrintMove(result.Move))
+	}
+}
+
+// TestSetPersonality checks that a preset sets the style of both searches and that the
+// style options set explicitly take precedence over it
+func TestSetPersonality(t *testing.T) {
+	e := New()
+	if err := e.SetPersonality("aggressive"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if e.UCT.Config.Contempt <= 0 || e.AB.Contempt != e.UCT.Config.Contempt || !e.UCT.Config.ContemptPhase || !e.AB.ContemptPhase {
+		t.Errorf("expected a positive phased contempt in both searches, got %d and %d", e.UCT.Config.Contempt, e.AB.Contempt)
+	}
+	if _, ok := e.UCT.Config.Policy.(uct.TacticalPolicy); !ok {
+		t.Errorf("expected the tactical rollout policy, got %T", e.UCT.Config.Policy)
+	}
+
+	e.SetContempt(10)
+	if err := e.SetPersonality("solid"); err != nil || e.UCT.Config.Contempt != 10 || e.AB.Contempt != 10 {
+		t.Errorf("expected the explicit contempt to be kept, got %d and %d (%v)", e.UCT.Config.Contempt, e.AB.Contempt, err)
+	}
+	if _, ok := e.UCT.Config.Policy.(uct.CapturePolicy); !ok {
+		t.Errorf("expected the capture rollout policy of the preset, got %T", e.UCT.Config.Policy)
+	}
+
+	e.ResetStyle()
+	if err := e.SetPersonality(DefaultPersonality); err != nil || e.UCT.Config.Contempt != 0 || e.Temperature != 0 || e.UCT.Config.ContemptPhase {
+		t.Errorf("expected the defaults to be restored, got contempt %d, temperature %f (%v)",
+			e.UCT.Config.Contempt, e.Temperature, err)
+	}
+	if err := e.SetPersonality("reckless"); err != ErrUnknownPersonality {
+		t.Errorf("expected ErrUnknownPersonality, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"slinky/board"
+	"time"
+)
+
+// Parameters of the time management
+const (
+	openingMovesLeft = 40   // expected number of moves until the end of the game in the opening
+	endgameMovesLeft = 20   // expected number of moves until the end of the game in the endgame
+	incrementShare   = 0.75 // part of the increment added to the soft budget
+	hardRatio        = 4    // the hard budget is at most hardRatio times the soft budget
+	maxTimeShare     = 0.5  // part of the remaining time a single move may use at most
+	lastMoveShare    = 0.9  // part of the remaining time the last move before the time control may use
+
+	unstableScale  = 1.5  // scale of the soft budget if the best move changed late
+	closeScale     = 1.3  // scale of the soft budget if the top two moves have a similar number of visits
+	dominantScale  = 0.5  // scale of the soft budget if the best move has most of the visits
+	closeGap       = 0.05 // visit difference (relative to all visits) below which the top two moves are close
+	dominantShare  = 0.75 // visit share above which the best move dominates
+	minBudget      = time.Millisecond
+	checkFrequency = 10 * time.Millisecond // time between two checks of the soft budget
+)
+
+// timeManager decides when a search stops. The soft budget is the time the move is
+// expected to take, it is stretched if the search is unstable and shrunk if the best
+// move is clear. The hard budget is never exceeded
+type timeManager struct {
+	soft, hard time.Duration
+	fixed      bool // the time per move is fixed (movetime), the soft budget is not scaled
+	bestMove   int
+	lastChange time.Duration // time of the last change of the best move
+	scale      float64
+	agree      func() bool // tells if the search may stop at its budget, nil if it always may (see waitForAgreement)
+	extension  float64     // part of the budget the search may be extended by until agree returns true
+}
+
+// moveTime returns the time of a fixed time search after subtracting the overhead
+func moveTime(limits Limits, overhead time.Duration) time.Duration {
+	if limits.MoveTime-overhead < minBudget {
+		return minBudget
+	}
+	return limits.MoveTime - overhead
+}
+
+// newTimeManager computes the time budgets of a search with a clock (or a fixed move
+// time). overhead is the time lost per move outside of the search (i.e. in the GUI)
+func newTimeManager(limits Limits, overhead time.Duration, pos *board.ChessBoard) *timeManager {
+	tm := &timeManager{bestMove: board.NoMove, scale: 1}
+	if limits.MoveTime > 0 {
+		tm.soft = moveTime(limits, overhead)
+		tm.hard, tm.fixed = tm.soft, true
+		return tm
+	}
+
+	available := limits.Time - overhead
+	if available < minBudget {
+		// the overhead eats up the clock, the search still needs some time to find a move
+		available = minBudget
+	}
+	movesLeft, share := movesLeft(pos), maxTimeShare
+	if limits.MovesToGo > 0 && limits.MovesToGo < movesLeft {
+		movesLeft = limits.MovesToGo
+		if movesLeft == 1 {
+			share = lastMoveShare
+		}
+	}
+
+	tm.soft = available / time.Duration(movesLeft)
+	if available > limits.Increment {
+		// the increment is only relied on if the clock would not run out without it
+		tm.soft += time.Duration(float64(limits.Increment) * incrementShare)
+	}
+	tm.hard = tm.soft * hardRatio
+	if maxHard := time.Duration(float64(available) * share); tm.hard > maxHard {
+		tm.hard = maxHard
+	}
+	if tm.hard < minBudget {
+		tm.hard = minBudget
+	}
+	if tm.soft < minBudget {
+		tm.soft = minBudget
+	}
+	if tm.soft > tm.hard {
+		tm.soft = tm.hard
+	}
+	return tm
+}
+
+// waitForAgreement lets the search go on after its budget (by at most extension times the
+// budget) until agree returns true. It is used by the max-robust move selection which
+// searches until the max and the robust child agree
+func (tm *timeManager) waitForAgreement(agree func() bool, extension float64) {
+	tm.agree, tm.extension = agree, extension
+	if tm.fixed {
+		tm.hard = time.Duration(float64(tm.soft) * (1 + extension))
+	}
+}
+
+// movesLeft estimates the number of moves until the end of the game from the
+// material on the board: the fewer pieces, the fewer moves are left
+func movesLeft(pos *board.ChessBoard) int {
+	phase := 0
+	for _, piece := range []int{board.WhiteKnight, board.WhiteBishop, board.BlackKnight, board.BlackBishop} {
+		phase += pos.PieceCount(piece)
+	}
+	phase += 2 * (pos.PieceCount(board.WhiteRook) + pos.PieceCount(board.BlackRook))
+	phase += 4 * (pos.PieceCount(board.WhiteQueen) + pos.PieceCount(board.BlackQueen))
+	if phase > 24 {
+		phase = 24
+	}
+	return endgameMovesLeft + (openingMovesLeft-endgameMovesLeft)*phase/24
+}
+
+// update records the state of the search elapsed after the clock started. share is
+// the visit share of the most visited move and gap its visit difference to the
+// runner-up relative to all visits (both negative if the search has no visits)
+func (tm *timeManager) update(elapsed time.Duration, bestMove int, share, gap float64) {
+	if bestMove != tm.bestMove {
+		if tm.bestMove != board.NoMove {
+			tm.lastChange = elapsed
+		}
+		tm.bestMove = bestMove
+	}
+
+	tm.scale = 1
+	if tm.lastChange > tm.soft/2 {
+		tm.scale *= unstableScale
+	}
+	if share > dominantShare {
+		tm.scale *= dominantScale
+	} else if gap >= 0 && gap < closeGap {
+		tm.scale *= closeScale
+	}
+}
+
+// budget returns the time the search may take given its current stability
+func (tm *timeManager) budget() time.Duration {
+	if tm.fixed {
+		return tm.soft
+	}
+	budget := time.Duration(float64(tm.soft) * tm.scale)
+	if budget > tm.hard {
+		return tm.hard
+	}
+	return budget
+}
+
+// mayStop returns true if the search may stop after elapsed, i.e. once its budget is used
+// up and the search agrees or its extension is used up as well
+func (tm *timeManager) mayStop(elapsed time.Duration) bool {
+	budget := tm.budget()
+	if elapsed < budget {
+		return false
+	}
+	return tm.agree == nil || tm.agree() || elapsed >= time.Duration(float64(budget)*(1+tm.extension))
+}
+
+// run stops the search once its time budget is used up (see mayStop). When pondering
+// the clock starts once ponderHit is closed. best returns the current best move, share
+// and gap (see update)
+func (tm *timeManager) run(ctx context.Context, stop context.CancelFunc, ponderHit <-chan struct{}, best func() (int, float64, float64)) {
+	if ponderHit != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ponderHit:
+		}
+	}
+
+	start := time.Now()
+	hardStop := time.AfterFunc(tm.hard, stop)
+	defer hardStop.Stop()
+	ticker := time.NewTicker(checkFrequency)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			move, share, gap := best()
+			elapsed := time.Since(start)
+			tm.update(elapsed, move, share, gap)
+			if tm.mayStop(elapsed) {
+				stop()
+				return
+			}
+		}
+	}
+}
+
+// timeControl runs the time manager of a search (if it has one)
+type timeControl struct {
+	tm        *timeManager
+	stop      context.CancelFunc // stops the search
+	ponderHit <-chan struct{}
+}
+
+// start runs the time manager in its own goroutine. best returns the current best move
+// with its share and gap (see timeManager.update). The returned function is called once
+// the search has finished, it waits until the time manager is done
+func (c timeControl) start(ctx context.Context, best func() (int, float64, float64)) func() {
+	if c.tm == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.tm.run(ctx, c.stop, c.ponderHit, best)
+	}()
+	return func() {
+		c.stop()
+		<-done
+	}
+}
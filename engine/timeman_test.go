@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"slinky/board"
+	"slinky/uct"
+	"testing"
+	"time"
+)
+
+// TestTimeBudgets checks that the budgets stay within the clock, also in fast games
+func TestTimeBudgets(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	tests := []Limits{
+		{Time: 5 * time.Minute},
+		{Time: time.Minute, Increment: time.Second},
+		{Time: 10 * time.Second, MovesToGo: 5},
+		{Time: 2 * time.Second, MovesToGo: 1},
+		{Time: 200 * time.Millisecond, Increment: 2 * time.Second},
+		{Time: 20 * time.Millisecond},
+		{Time: 20 * time.Millisecond, Increment: time.Second},
+		{Time: time.Millisecond, MovesToGo: 1},
+	}
+	overhead := 30 * time.Millisecond
+	for _, limits := range tests {
+		tm := newTimeManager(limits, overhead, &pos)
+		if tm.soft > tm.hard || tm.soft < minBudget || tm.hard < minBudget {
+			t.Errorf("%+v: invalid budgets soft %v hard %v", limits, tm.soft, tm.hard)
+		}
+		if available := limits.Time - overhead; available > 0 && tm.hard > time.Duration(float64(available)*lastMoveShare) {
+			t.Errorf("%+v: hard budget %v exceeds the time on the clock", limits, tm.hard)
+		}
+	}
+
+	if tm := newTimeManager(Limits{MoveTime: time.Second}, overhead, &pos); tm.budget() != time.Second-overhead {
+		t.Errorf("expected a fixed budget of %v, got %v", time.Second-overhead, tm.budget())
+	}
+}
+
+// TestTimeScaling checks that the soft budget stretches when the search is unstable and
+// shrinks when one move dominates
+func TestTimeScaling(t *testing.T) {
+	tm := &timeManager{soft: time.Second, hard: 4 * time.Second, bestMove: board.NoMove, scale: 1}
+
+	tm.update(100*time.Millisecond, 1, 0.4, 0.2)
+	if tm.budget() != time.Second {
+		t.Errorf("expected the soft budget, got %v", tm.budget())
+	}
+	tm.update(200*time.Millisecond, 1, 0.9, 0.8)
+	if tm.budget() >= time.Second {
+		t.Errorf("expected a shorter budget when one move dominates, got %v", tm.budget())
+	}
+	tm.update(300*time.Millisecond, 1, 0.4, 0.01)
+	if tm.budget() <= time.Second {
+		t.Errorf("expected a longer budget when the top two moves are close, got %v", tm.budget())
+	}
+	tm.update(800*time.Millisecond, 2, 0.4, 0.2)
+	if tm.budget() <= time.Second {
+		t.Errorf("expected a longer budget after a late change of the best move, got %v", tm.budget())
+	}
+}
+
+// TestTimeAgreement checks that the budget is extended until the search agrees
+func TestTimeAgreement(t *testing.T) {
+	tm := &timeManager{soft: time.Second, hard: 4 * time.Second, bestMove: board.NoMove, scale: 1}
+	agree := false
+	tm.waitForAgreement(func() bool { return agree }, 0.5)
+
+	if tm.mayStop(900 * time.Millisecond) {
+		t.Errorf("expected the search to go on before the budget is used up")
+	}
+	if tm.mayStop(1200 * time.Millisecond) {
+		t.Errorf("expected the budget to be extended until the search agrees")
+	}
+	if !tm.mayStop(1500 * time.Millisecond) {
+		t.Errorf("expected the search to stop once the extension is used up")
+	}
+	agree = true
+	if !tm.mayStop(1200 * time.Millisecond) {
+		t.Errorf("expected the search to stop once it agrees")
+	}
+
+	fixed := newTimeManager(Limits{MoveTime: time.Second}, 0, nil)
+	fixed.waitForAgreement(func() bool { return false }, 0.5)
+	if fixed.budget() != time.Second || fixed.hard != 1500*time.Millisecond {
+		t.Errorf("expected a fixed budget of 1s extended to 1.5s, got %v and %v", fixed.budget(), fixed.hard)
+	}
+}
+
+// TestSearchMaxRobustClock checks that a search with a clock and the max-robust move
+// selection keeps searching until the max and the robust child agree (or the extension
+// is used up) and stays within the hard budget
+func TestSearchMaxRobustClock(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	e := New()
+	e.OwnBook = false
+	e.UCT.Config.MoveSelection = uct.MaxRobustChildName
+	limits := Limits{Time: 4 * time.Second, MovesToGo: 20}
+	tm := newTimeManager(limits, e.MoveOverhead, &pos)
+
+	start := time.Now()
+	result, err := e.Search(context.Background(), &pos, limits)
+	elapsed := time.Since(start)
+	if err != nil || result.Move == board.NoMove {
+		t.Fatalf("expected a move, got %v", err)
+	}
+	if elapsed > tm.hard+100*time.Millisecond {
+		t.Errorf("the search took %v with a hard budget of %v", elapsed, tm.hard)
+	}
+	// the shortest budget is the dominant scale of the soft budget
+	minExtended := time.Duration(float64(tm.soft) * dominantScale * (1 + uct.MaxRobustExtension))
+	if !e.UCT.MaxRobustAgree() && elapsed < minExtended {
+		t.Errorf("the search stopped after %v before the max and the robust child agree", elapsed)
+	}
+}
+
+// TestSearchKeepsTime checks that a search with a nearly empty clock returns in time
+func TestSearchKeepsTime(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	for _, algorithm := range Names {
+		e := New()
+		e.Algorithm = algorithm
+		start := time.Now()
+		result, err := e.Search(context.Background(), &pos, Limits{Time: 300 * time.Millisecond})
+		if err != nil || result.Move == board.NoMove {
+			t.Fatalf("%s: expected a move, got %v", algorithm, err)
+		}
+		if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+			t.Errorf("%s: the search took %v with 300ms on the clock", algorithm, elapsed)
+		}
+	}
+}
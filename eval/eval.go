@@ -0,0 +1,280 @@
+package eval
+
+import (
+	"fmt"
+	"slinky/board"
+)
+
+// Score holds a middlegame and an endgame score in centipawns
+type Score struct {
+	Mg int
+	Eg int
+}
+
+func (s *Score) add(mg, eg int) {
+	s.Mg += mg
+	s.Eg += eg
+}
+
+// Evaluation terms
+const (
+	Material int = iota
+	PieceSquare
+	PawnStructure
+	Mobility
+	KingSafety
+	BishopPair
+	TermCount
+)
+
+// TermNames names of the evaluation terms (indexed by term)
+var TermNames = [TermCount]string{"Material", "Piece squares", "Pawn structure", "Mobility", "King safety", "Bishop pair"}
+
+// TotalPhase game phase of the starting position, a phase of 0 means only kings and pawns are left
+const TotalPhase = 24
+
+// Breakdown holds the score of every evaluation term for each side
+type Breakdown struct {
+	Terms [2][TermCount]Score // scores from the point of view of each side (indexed by colour)
+	Phase int                 // game phase between 0 (endgame) and TotalPhase (opening)
+	Side  int                 // side to move
+}
+
+// Total returns the middlegame and endgame score of all terms from white's point of view
+func (b *Breakdown) Total() Score {
+	var total Score
+	for term := 0; term < TermCount; term++ {
+		total.add(b.Terms[board.White][term].Mg-b.Terms[board.Black][term].Mg,
+			b.Terms[board.White][term].Eg-b.Terms[board.Black][term].Eg)
+	}
+	return total
+}
+
+// Score returns the tapered score from the point of view of the side to move
+func (b *Breakdown) Score() int {
+	total := b.Total()
+	score := (total.Mg*b.Phase + total.Eg*(TotalPhase-b.Phase)) / TotalPhase
+	if b.Side == board.Black {
+		return -score
+	}
+	return score
+}
+
+func (b *Breakdown) String() string {
+	line := fmt.Sprintf("%-16s %13s %13s %13s\n", "Term", "White", "Black", "Total")
+	line += fmt.Sprintf("%-16s %6s %6s %6s %6s %6s %6s\n", "", "MG", "EG", "MG", "EG", "MG", "EG")
+	for term := 0; term < TermCount; term++ {
+		white, black := b.Terms[board.White][term], b.Terms[board.Black][term]
+		line += fmt.Sprintf("%-16s %6d %6d %6d %6d %6d %6d\n", TermNames[term],
+			white.Mg, white.Eg, black.Mg, black.Eg, white.Mg-black.Mg, white.Eg-black.Eg)
+	}
+	total := b.Total()
+	line += fmt.Sprintf("%-16s %41d %6d\n", "Total", total.Mg, total.Eg)
+	line += fmt.Sprintf("Phase: %d/%d\n", b.Phase, TotalPhase)
+	line += fmt.Sprintf("Score (side to move): %d\n", b.Score())
+	return line
+}
+
+// Evaluate returns the static evaluation of the position in centipawns from
+// the point of view of the side to move
+func Evaluate(pos *board.ChessBoard) int {
+	b := Explain(pos)
+	return b.Score()
+}
+
+// Contempt returns the contempt (in centipawns) of the side to move in the position:
+// the amount by which a draw is valued below an even position. If phased is true the
+// contempt shrinks with the material on the board, from the full value in the
+// opening to none when only kings and pawns are left
+func Contempt(pos *board.ChessBoard, contempt int, phased bool) int {
+	if !phased || contempt == 0 {
+		return contempt
+	}
+	b := Explain(pos)
+	return contempt * b.Phase / TotalPhase
+}
+
+// pawnInfo holds the location of the pawns of both sides needed for the pawn structure terms
+type pawnInfo struct {
+	count    [2][board.RowSize]int // number of pawns per colour and file
+	rearmost [2][board.RowSize]int // relative rank of the least advanced pawn per colour and file
+	foremost [2][board.RowSize]int // relative rank of the most advanced pawn per colour and file
+}
+
+// relativeRank returns the rank as seen from the given side i.e. Rank8 for white is Rank1 for black
+func relativeRank(rank, side int) int {
+	if side == board.White {
+		return rank
+	}
+	return board.Rank8 - rank
+}
+
+// pieceType converts a piece to its white counterpart i.e. BlackKnight -> WhiteKnight
+func pieceType(piece int) int {
+	if piece > board.WhiteKing {
+		return piece - board.WhiteKing
+	}
+	return piece
+}
+
+// Explain evaluates the position and returns the score of every term
+func Explain(pos *board.ChessBoard) Breakdown {
+	b := Breakdown{Side: pos.Side}
+	var pawns pawnInfo
+	var kingSq [2]int
+	bishops := [2]int{}
+
+	for side := board.White; side <= board.Black; side++ {
+		for file := 0; file < board.RowSize; file++ {
+			pawns.rearmost[side][file] = board.RankNone
+			pawns.foremost[side][file] = -1
+		}
+	}
+
+	// first pass: material, piece squares, phase and the pawn/king locations
+	for sq64 := 0; sq64 < board.InnerSquareNum; sq64++ {
+		sq := board.Sq64ToSq120[sq64]
+		piece := pos.Pieces[sq]
+		if piece == board.Empty {
+			continue
+		}
+
+		side := board.PieceColour[piece]
+		kind := pieceType(piece)
+		file, rank := board.FilesBoard[sq], relativeRank(board.RanksBoard[sq], side)
+		tableIdx := (board.Rank8-rank)*board.RowSize + file
+
+		b.Terms[side][Material].add(materialMg[kind], materialEg[kind])
+		b.Terms[side][PieceSquare].add(pstMg[kind][tableIdx], pstEg[kind][tableIdx])
+		b.Phase += phaseWeight[kind]
+
+		switch kind {
+		case board.WhitePawn:
+			pawns.count[side][file]++
+			if rank < pawns.rearmost[side][file] {
+				pawns.rearmost[side][file] = rank
+			}
+			if rank > pawns.foremost[side][file] {
+				pawns.foremost[side][file] = rank
+			}
+		case board.WhiteBishop:
+			bishops[side]++
+		case board.WhiteKing:
+			kingSq[side] = sq
+		}
+	}
+
+	if b.Phase > TotalPhase {
+		b.Phase = TotalPhase // early promotions
+	}
+
+	for side := board.White; side <= board.Black; side++ {
+		if bishops[side] >= 2 {
+			b.Terms[side][BishopPair].add(bishopPairMg, bishopPairEg)
+		}
+	}
+
+	// second pass: terms that depend on the location of the other pieces
+	for sq64 := 0; sq64 < board.InnerSquareNum; sq64++ {
+		sq := board.Sq64ToSq120[sq64]
+		piece := pos.Pieces[sq]
+		if piece == board.Empty {
+			continue
+		}
+
+		side := board.PieceColour[piece]
+		switch kind := pieceType(piece); kind {
+		case board.WhitePawn:
+			evaluatePawn(&b, &pawns, sq, side)
+		case board.WhiteKnight, board.WhiteBishop, board.WhiteRook, board.WhiteQueen:
+			mobility := countMobility(pos, sq, piece, side)
+			b.Terms[side][Mobility].add(mobility*mobilityMg[kind], mobility*mobilityEg[kind])
+		}
+	}
+
+	for side := board.White; side <= board.Black; side++ {
+		evaluateKingSafety(&b, &pawns, kingSq[side], side)
+	}
+
+	return b
+}
+
+// evaluatePawn adds the passed, isolated and doubled pawn terms of a single pawn
+func evaluatePawn(b *Breakdown, pawns *pawnInfo, sq, side int) {
+	file, rank := board.FilesBoard[sq], relativeRank(board.RanksBoard[sq], side)
+	enemy := side ^ 1
+
+	passed, isolated := true, true
+	for f := file - 1; f <= file+1; f++ {
+		if f < board.FileA || f > board.FileH {
+			continue
+		}
+		if f != file && pawns.count[side][f] > 0 {
+			isolated = false
+		}
+		// an enemy pawn in front of the pawn (on the same or an adjacent file) can stop it.
+		// the ranks of the enemy pawns are relative to the enemy, so they are converted to our view
+		if pawns.count[enemy][f] > 0 && board.Rank8-pawns.rearmost[enemy][f] > rank {
+			passed = false
+		}
+	}
+
+	if passed {
+		b.Terms[side][PawnStructure].add(passedMg[rank], passedEg[rank])
+	}
+	if isolated {
+		b.Terms[side][PawnStructure].add(isolatedMg, isolatedEg)
+	}
+	// every pawn except the most advanced one on its file counts as doubled
+	if pawns.count[side][file] > 1 && rank != pawns.foremost[side][file] {
+		b.Terms[side][PawnStructure].add(doubledMg, doubledEg)
+	}
+}
+
+// countMobility counts the squares a piece can move to (empty squares or squares with an enemy piece)
+func countMobility(pos *board.ChessBoard, sq, piece, side int) int {
+	mobility := 0
+	slider := !board.IsPieceKnight[piece]
+	for i := 0; i < board.NumberOfDir[piece]; i++ {
+		dir := board.PieceDir[piece][i]
+		for targetSq := sq + dir; pos.Pieces[targetSq] != board.OffBoard; targetSq += dir {
+			target := pos.Pieces[targetSq]
+			if target != board.Empty {
+				if board.PieceColour[target] != side {
+					mobility++
+				}
+				break
+			}
+			mobility++
+			if !slider {
+				break
+			}
+		}
+	}
+	return mobility
+}
+
+// evaluateKingSafety adds the pawn shield in front of the king (middlegame only)
+func evaluateKingSafety(b *Breakdown, pawns *pawnInfo, kingSq, side int) {
+	kingFile, kingRank := board.FilesBoard[kingSq], relativeRank(board.RanksBoard[kingSq], side)
+
+	for f := kingFile - 1; f <= kingFile+1; f++ {
+		if f < board.FileA || f > board.FileH {
+			continue
+		}
+
+		if pawns.count[side][f] == 0 {
+			b.Terms[side][KingSafety].add(openFileNearKing, 0)
+			continue
+		}
+
+		switch pawns.rearmost[side][f] - kingRank {
+		case 1:
+			b.Terms[side][KingSafety].add(shieldClose, 0)
+		case 2:
+			b.Terms[side][KingSafety].add(shieldFar, 0)
+		default:
+			b.Terms[side][KingSafety].add(shieldMissing, 0)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package eval
+
+import (
+	"slinky/board"
+	"strings"
+	"testing"
+)
+
+var symmetryFens = []string{
+	board.StartFen,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+	"2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1",
+	"r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - 0 1",
+	"8/3q1p2/8/5P2/4Q3/8/8/8 w - - 0 2",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"6k1/5ppp/8/8/8/8/1P3PPP/6K1 b - - 0 1",
+}
+
+// mirrorFen flips the colours of a position i.e. white pieces become black pieces on the mirrored squares
+func mirrorFen(fen string) string {
+	fields := strings.Fields(fen)
+
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+
+	if fields[2] != "-" {
+		castling := ""
+		for _, c := range "KQkq" {
+			if strings.ContainsRune(swapCase(fields[2]), c) {
+				castling += string(c)
+			}
+		}
+		fields[2] = castling
+	}
+
+	if fields[3] != "-" {
+		rank := '1' + '8' - rune(fields[3][1])
+		fields[3] = string(fields[3][0]) + string(rank)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		} else if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return r
+	}, s)
+}
+
+// TestEvaluateSymmetry checks that a position and its colour flipped mirror get the same
+// score from the point of view of the side to move and that every term is mirrored
+func TestEvaluateSymmetry(t *testing.T) {
+	board.AllInit()
+
+	for _, fen := range symmetryFens {
+		pos := board.CreateBoard()
+		pos.ParseFen(fen)
+		mirrored := board.CreateBoard()
+		mirrored.ParseFen(mirrorFen(fen))
+
+		score, mirroredScore := Evaluate(&pos), Evaluate(&mirrored)
+		if score != mirroredScore {
+			t.Errorf("Evaluation is not symmetric for %s: %d != %d (mirror %s)", fen, score, mirroredScore, mirrorFen(fen))
+		}
+
+		b, mb := Explain(&pos), Explain(&mirrored)
+		for term := 0; term < TermCount; term++ {
+			if b.Terms[board.White][term] != mb.Terms[board.Black][term] ||
+				b.Terms[board.Black][term] != mb.Terms[board.White][term] {
+				t.Errorf("%s term is not symmetric for %s", TermNames[term], fen)
+			}
+		}
+	}
+}
+
+// TestEvaluateStartPosition checks that the starting position is balanced
+func TestEvaluateStartPosition(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	b := Explain(&pos)
+	if b.Phase != TotalPhase {
+		t.Errorf("Phase of starting position is %d, expected %d", b.Phase, TotalPhase)
+	}
+	if score := b.Score(); score != 0 {
+		t.Errorf("Starting position is evaluated as %d, expected 0", score)
+	}
+}
+
+// TestContempt checks that the phased contempt is full in the opening and gone in a pawn ending
+func TestContempt(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+	if contempt := Contempt(&pos, 40, true); contempt != 40 {
+		t.Errorf("Contempt in the starting position is %d, expected 40", contempt)
+	}
+
+	pos.ParseFen("4k3/pp6/8/8/8/8/6PP/4K3 w - - 0 1")
+	if contempt := Contempt(&pos, 40, true); contempt != 0 {
+		t.Errorf("Phased contempt in a pawn ending is %d, expected 0", contempt)
+	}
+	if contempt := Contempt(&pos, 40, false); contempt != 40 {
+		t.Errorf("Contempt in a pawn ending is %d, expected 40", contempt)
+	}
+}
@@ -0,0 +1,245 @@
+// Package mate implements a search for forced mates. It is a depth-bounded AND/OR
+// search: at the nodes of the attacker one move has to mate, at the nodes of the
+// defender every move has to. The mate distance is deepened one move at a time, so
+// the first mate found is the shortest one
+package mate
+
+import (
+	"context"
+	"slinky/board"
+)
+
+const (
+	checkStopInterval = 2047    // the search checks if it has to stop every checkStopInterval+1 nodes
+	maxTableEntries   = 1 << 22 // the table of solved positions is cleared when it gets bigger
+)
+
+// Result is the outcome of a mate search
+type Result struct {
+	Move  int    // first move of the mate (board.NoMove if no mate was found)
+	Mate  int    // moves until mate, 0 if no mate was found
+	PV    []int  // mating line in which the defender delays the mate as long as possible
+	Depth int    // mate distance in moves that was searched completely
+	Nodes uint64 // number of nodes searched
+}
+
+// tableKey identifies a position together with the number of plies left to mate in
+type tableKey struct {
+	posKey uint64
+	plies  int
+}
+
+// search holds the state of a single mate search
+type search struct {
+	pos     *board.ChessBoard
+	ctx     context.Context
+	stopped bool
+	nodes   uint64
+	table   map[tableKey]bool // solved attacker positions: true if they mate in the plies
+	// number of draws by repetition or the fifty move rule found so far. These draws
+	// depend on the moves that led to the position, so results that saw one are not
+	// stored in the table
+	historyDraws int
+}
+
+// Search looks for a mate in at most maxMoves moves of the side to move, trying only the
+// given root moves (all legal moves if rootMoves is empty). It stops early once ctx is
+// cancelled. report (if not nil) is called after every completed mate distance. The
+// position is not modified
+func Search(ctx context.Context, pos *board.ChessBoard, maxMoves int, rootMoves []int, report func(Result)) Result {
+	searchPos := *pos
+	s := &search{pos: &searchPos, ctx: ctx, table: make(map[tableKey]bool)}
+	if len(rootMoves) == 0 {
+		rootMoves = searchPos.GetMoves()
+	}
+
+	result := Result{Move: board.NoMove}
+	for moves := 1; moves <= maxMoves; moves++ {
+		plies := 2*moves - 1
+		move := board.NoMove
+		for _, rootMove := range s.orderMoves(rootMoves, plies == 1) {
+			if s.mates(rootMove, plies) {
+				move = rootMove
+				break
+			}
+			if s.stopped {
+				break
+			}
+		}
+		if s.stopped {
+			break
+		}
+
+		result.Depth = moves
+		result.Nodes = s.nodes
+		if move != board.NoMove {
+			result.Move = move
+			result.Mate = moves
+			result.PV = append([]int{move}, s.defence(move, plies)...)
+		}
+		if report != nil {
+			report(result)
+		}
+		if move != board.NoMove {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	return result
+}
+
+// checkStop marks the search as stopped once ctx is cancelled
+func (s *search) checkStop() {
+	if s.nodes&checkStopInterval == 0 && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+}
+
+// orderMoves returns the moves that give check first, as they are the most forcing
+// ones. With checksOnly the other moves are left out (only a check can mate at once)
+func (s *search) orderMoves(moves []int, checksOnly bool) []int {
+	ordered := make([]int, 0, len(moves))
+	checks := 0
+	for _, move := range moves {
+		s.pos.MakeMove(move)
+		check := s.pos.InCheck()
+		s.pos.TakeMove()
+		if check {
+			ordered = append(ordered, move)
+			ordered[checks], ordered[len(ordered)-1] = ordered[len(ordered)-1], ordered[checks]
+			checks++
+		} else if !checksOnly {
+			ordered = append(ordered, move)
+		}
+	}
+	return ordered
+}
+
+// mates returns true if the move of the attacker forces a mate within plies (including the move)
+func (s *search) mates(move, plies int) bool {
+	s.pos.MakeMove(move)
+	var mate bool
+	if plies == 1 {
+		mate = s.pos.InCheck() && !s.pos.HasLegalMove()
+	} else {
+		mate = s.defend(plies - 1)
+	}
+	s.pos.TakeMove()
+	return mate
+}
+
+// attack returns true if the side to move can mate within plies (an odd number)
+func (s *search) attack(plies int) bool {
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return false
+	}
+
+	if s.drawn() {
+		return false
+	}
+	key := tableKey{s.pos.PosKey(), plies}
+	if mate, found := s.table[key]; found {
+		return mate
+	}
+
+	mate, historyDraws := false, s.historyDraws
+	for _, move := range s.orderMoves(s.pos.GetMoves(), plies == 1) {
+		if s.mates(move, plies) {
+			mate = true
+			break
+		}
+		if s.stopped {
+			return false
+		}
+	}
+
+	if s.historyDraws != historyDraws {
+		return mate
+	}
+	if len(s.table) >= maxTableEntries {
+		s.table = make(map[tableKey]bool)
+	}
+	s.table[key] = mate
+	return mate
+}
+
+// drawn returns true if the position is drawn. Draws by repetition or the fifty move
+// rule are counted (see historyDraws)
+func (s *search) drawn() bool {
+	if !s.pos.IsDrawn() {
+		return false
+	}
+	if !s.pos.IsPositionDraw() {
+		s.historyDraws++
+	}
+	return true
+}
+
+// defend returns true if the side to move gets mated within plies (an even number)
+// whatever it plays
+func (s *search) defend(plies int) bool {
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return false
+	}
+
+	moves := s.pos.GetMoves()
+	if len(moves) == 0 {
+		return s.pos.InCheck() // mated already or stalemate
+	}
+	if s.drawn() {
+		return false
+	}
+	for _, move := range moves {
+		s.pos.MakeMove(move)
+		mate := s.attack(plies - 1)
+		s.pos.TakeMove()
+		if !mate || s.stopped {
+			return false
+		}
+	}
+	return true
+}
+
+// defence returns the rest of the mating line after the move of the attacker which mates
+// within plies. The defender picks the reply after which the mate takes longest
+func (s *search) defence(move, plies int) []int {
+	if plies == 1 {
+		return nil
+	}
+	s.pos.MakeMove(move)
+	defer s.pos.TakeMove()
+
+	reply, replyPlies := board.NoMove, 0
+	for _, defence := range s.pos.GetMoves() {
+		s.pos.MakeMove(defence)
+		// the shortest mate after this reply
+		p := 1
+		for p < plies-2 && !s.attack(p) {
+			p += 2
+		}
+		s.pos.TakeMove()
+		if p > replyPlies {
+			reply, replyPlies = defence, p
+		}
+	}
+	if reply == board.NoMove {
+		return nil // mated by the move
+	}
+
+	line := []int{reply}
+	s.pos.MakeMove(reply)
+	for _, next := range s.orderMoves(s.pos.GetMoves(), replyPlies == 1) {
+		if s.mates(next, replyPlies) {
+			line = append(line, next)
+			line = append(line, s.defence(next, replyPlies)...)
+			break
+		}
+	}
+	s.pos.TakeMove()
+	return line
+}
@@ -0,0 +1,112 @@
+package mate
+
+import (
+	"context"
+	"slinky/board"
+	"testing"
+)
+
+// TestSearchFindsMate checks the shortest mates and their lines
+func TestSearchFindsMate(t *testing.T) {
+	board.AllInit()
+
+	tests := []struct {
+		fen  string
+		move string
+		mate int
+	}{
+		{"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", "d1d8", 1},
+		{"7k/8/5K2/8/8/8/8/6R1 w - - 0 1", "f6f7", 2},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7", 1},
+		{"6k1/8/6K1/8/8/8/8/R7 w - - 0 1", "a1a8", 1},
+	}
+
+	for _, test := range tests {
+		pos := board.CreateBoard()
+		pos.ParseFen(test.fen)
+		fen := pos.GenerateFen()
+
+		result := Search(context.Background(), &pos, 3, nil, nil)
+		if board.PrintMove(result.Move) != test.move || result.Mate != test.mate {
+			t.Errorf("%s: expected mate in %d with %s, got mate in %d with %s",
+				test.fen, test.mate, test.move, result.Mate, board.PrintMove(result.Move))
+		}
+		if len(result.PV) != 2*test.mate-1 {
+			t.Errorf("%s: expected a line of %d plies, got %d", test.fen, 2*test.mate-1, len(result.PV))
+		}
+
+		// the line has to end in checkmate
+		for _, move := range result.PV {
+			pos.MakeMove(move)
+		}
+		if !pos.InCheck() || pos.HasLegalMove() {
+			t.Errorf("%s: the line %v does not end in checkmate", test.fen, result.PV)
+		}
+		for range result.PV {
+			pos.TakeMove()
+		}
+		if pos.GenerateFen() != fen {
+			t.Errorf("%s: the position was modified", test.fen)
+		}
+	}
+}
+
+// TestSearchNoMate checks that no mate is reported where there is none within the distance
+func TestSearchNoMate(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("7k/8/5K2/8/8/8/8/6R1 w - - 0 1")
+
+	reports := 0
+	result := Search(context.Background(), &pos, 1, nil, func(Result) { reports++ })
+	if result.Mate != 0 || result.Move != board.NoMove || result.Depth != 1 || reports != 1 {
+		t.Errorf("expected no mate in 1 after one report, got %+v after %d reports", result, reports)
+	}
+
+	// the mate in one is left out by the root moves
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	rootMoves := []int{pos.ParseMove("d1d2"), pos.ParseMove("g1f1")}
+	if result := Search(context.Background(), &pos, 2, rootMoves, nil); result.Mate != 0 {
+		t.Errorf("expected no mate with the root moves %v, got %+v", rootMoves, result)
+	}
+}
+
+// TestSearchTableIgnoresRepetitions checks that results which depend on a repetition
+// of the defender or the attacker are not stored in the table of solved positions
+func TestSearchTableIgnoresRepetitions(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+	s := &search{pos: &pos, ctx: context.Background(), table: make(map[tableKey]bool)}
+	s.attack(3)
+	if _, found := s.table[tableKey{pos.PosKey(), 3}]; !found {
+		t.Errorf("expected the start position to be stored in the table")
+	}
+
+	// after g1f3 the position is repeated for the third time
+	for i := 0; i < 2; i++ {
+		for _, move := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+			pos.MakeMove(pos.ParseMove(move))
+		}
+	}
+	s = &search{pos: &pos, ctx: context.Background(), table: make(map[tableKey]bool)}
+	s.attack(3)
+	if _, found := s.table[tableKey{pos.PosKey(), 3}]; found || s.historyDraws == 0 {
+		t.Errorf("expected the start position not to be stored after a repetition, %d draws found", s.historyDraws)
+	}
+
+	// the attacker to move is in a position repeated for the third time: no mate in 1
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	for i := 0; i < 2; i++ {
+		for _, move := range []string{"g1h1", "g8h8", "h1g1", "h8g8"} {
+			pos.MakeMove(pos.ParseMove(move))
+		}
+	}
+	s = &search{pos: &pos, ctx: context.Background(), table: make(map[tableKey]bool)}
+	if s.attack(1) || s.historyDraws == 0 {
+		t.Errorf("expected no mate in a drawn position, %d draws found", s.historyDraws)
+	}
+	if _, found := s.table[tableKey{pos.PosKey(), 1}]; found {
+		t.Errorf("expected the drawn position not to be stored in the table")
+	}
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"os"
 	"slinky/board"
 	"slinky/utils"
 	"strings"
@@ -29,11 +29,13 @@ const (
 )
 
 func normalMode(boardState *board.ChessBoard, info *board.SearchInfo) {
-	line := ""
 	fmt.Printf("Welcome to Slinky! Type 'slinky' for console mode...\n")
 
 	for {
-		line, _ = utils.GetInput("")
+		line, err := utils.GetInput("")
+		if err != nil {
+			break
+		}
 		if len(line) < 2 {
 			continue
 		}
@@ -59,10 +61,13 @@ func normalMode(boardState *board.ChessBoard, info *board.SearchInfo) {
 func main() {
 	board.AllInit()
 
+	seed := flag.Int64("seed", 0, "seed of the random choices of the engine (0 - random), the default of the Seed option")
+	flag.Parse()
+
 	boardState := board.CreateBoard()
-	var info board.SearchInfo
+	info := board.SearchInfo{Seed: *seed}
 
-	args := os.Args[1:] // args excluding program name
+	args := flag.Args() // args excluding program name and flags
 	argStr := strings.Join(args, " ")
 	fmt.Println(argStr)
 	argCommands := strings.Split(argStr, ", ")
@@ -0,0 +1,187 @@
+package uct
+
+import (
+	"slinky/board"
+	"unsafe"
+)
+
+// nodeIndex is the index of a node in the arena of its tree. Nodes are linked by
+// index: every node knows its parent, its first child and its next sibling
+type nodeIndex int32
+
+// noNode marks a missing link
+const noNode nodeIndex = -1
+
+const (
+	arenaChunkBits = 12
+	arenaChunkSize = 1 << arenaChunkBits // nodes per chunk, chunks are allocated when needed
+	arenaChunkMask = arenaChunkSize - 1
+
+	averageMoves = 40   // expected number of legal moves per node (used to estimate the memory of a node)
+	collectShare = 0.25 // part of the arena freed by a garbage collection of the tree
+)
+
+// NodeBytes is the estimated memory used by a node including its moves
+const NodeBytes = int(unsafe.Sizeof(Node{})) + averageMoves*int(unsafe.Sizeof(int(0)))
+
+// arena holds the nodes of a tree. The nodes are allocated in chunks up to a fixed
+// capacity and freed nodes are recycled, so the memory used by a tree is bounded.
+// A recycled node keeps its move buffer, which avoids allocating it again
+type arena struct {
+	chunks   [][]Node
+	capacity int
+	used     int       // number of nodes handed out from the chunks (including freed ones)
+	free     nodeIndex // first node of the free list (linked by sibling)
+	live     int       // number of nodes in use
+}
+
+// newArena creates an arena holding at most capacity nodes
+func newArena(capacity int) *arena {
+	if capacity < 2 {
+		capacity = 2
+	}
+	return &arena{capacity: capacity, free: noNode}
+}
+
+// node returns the node with the given index (nil for noNode)
+func (a *arena) node(i nodeIndex) *Node {
+	if i == noNode {
+		return nil
+	}
+	return &a.chunks[i>>arenaChunkBits][i&arenaChunkMask]
+}
+
+// full returns true if no node can be allocated
+func (a *arena) full() bool {
+	return a.live >= a.capacity
+}
+
+// alloc returns a new node for the position of state (nil if the arena is full)
+func (a *arena) alloc(move int, state *board.ChessBoard) *Node {
+	if a.full() {
+		return nil
+	}
+
+	var index nodeIndex
+	if a.free != noNode {
+		index = a.free
+		a.free = a.node(index).sibling
+	} else {
+		index = nodeIndex(a.used)
+		if a.used>>arenaChunkBits == len(a.chunks) {
+			a.chunks = append(a.chunks, make([]Node, arenaChunkSize))
+		}
+		a.used++
+	}
+	a.live++
+
+	n := a.node(index)
+	*n = Node{
+		move:            move,
+		index:           index,
+		parent:          noNode,
+		child:           noNode,
+		sibling:         noNode,
+		untriedMoves:    state.AppendMoves(n.untriedMoves[:0]),
+		playerJustMoved: state.GetPlayerJustMoved(),
+		posKey:          state.PosKey(),
+	}
+	return n
+}
+
+// release puts a node on the free list. Its children must have been released before
+func (a *arena) release(n *Node) {
+	n.sibling = a.free
+	a.free = n.index
+	a.live--
+}
+
+// reset releases all nodes at once
+func (a *arena) reset() {
+	a.used = 0
+	a.free = noNode
+	a.live = 0
+}
+
+// parent returns the parent of the node (nil for the root)
+func (a *arena) parent(n *Node) *Node {
+	return a.node(n.parent)
+}
+
+// child returns the first child of the node (nil if it has none)
+func (a *arena) child(n *Node) *Node {
+	return a.node(n.child)
+}
+
+// sibling returns the next child of the node's parent (nil if it is the last one)
+func (a *arena) sibling(n *Node) *Node {
+	return a.node(n.sibling)
+}
+
+// link adds child as the first child of n
+func (a *arena) link(n, child *Node) {
+	child.parent = n.index
+	child.sibling = n.child
+	n.child = child.index
+	n.children++
+}
+
+// releaseSubtree releases the node and all nodes below it
+func (a *arena) releaseSubtree(n *Node) {
+	for child := a.child(n); child != nil; {
+		next := a.sibling(child)
+		a.releaseSubtree(child)
+		child = next
+	}
+	a.release(n)
+}
+
+// releaseExcept releases the subtree of n except the subtree of keep
+func (a *arena) releaseExcept(n, keep *Node) {
+	if n == keep {
+		return
+	}
+	for child := a.child(n); child != nil; {
+		next := a.sibling(child)
+		a.releaseExcept(child, keep)
+		child = next
+	}
+	a.release(n)
+}
+
+// prune releases the children of n. Their moves become untried moves of n again,
+// so they can be expanded later
+func (a *arena) prune(n *Node) {
+	for child := a.child(n); child != nil; {
+		next := a.sibling(child)
+		n.untriedMoves = append(n.untriedMoves, child.move)
+		a.releaseSubtree(child)
+		child = next
+	}
+	n.child = noNode
+	n.children = 0
+	n.priors = nil // the priors and the move ordering are computed again when needed
+	n.ordered = false
+}
+
+// collect frees memory once the arena is full: the subtrees below the least visited
+// nodes are pruned until at least collectShare of the arena is free again. The visit
+// threshold of the pruned nodes doubles with every pass over the tree
+func (a *arena) collect(root *Node) {
+	target := int(float64(a.capacity) * collectShare)
+	for threshold := 2.0; a.capacity-a.live < target && threshold <= root.visits; threshold *= 2 {
+		a.pruneBelow(root, threshold)
+	}
+}
+
+// pruneBelow prunes the children of all nodes in the subtree of n with fewer than
+// threshold visits. The children of n itself are kept
+func (a *arena) pruneBelow(n *Node, threshold float64) {
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		if child.visits < threshold {
+			a.prune(child)
+		} else {
+			a.pruneBelow(child, threshold)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package uct
+
+import (
+	"slinky/board"
+	"testing"
+)
+
+// TestArenaRecycles checks that released nodes are reused and the capacity is kept
+func TestArenaRecycles(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	a := newArena(3)
+	root := createRootNode(a, &pos)
+	a.link(root, a.alloc(board.NoMove, &pos))
+	a.link(root, a.alloc(board.NoMove, &pos))
+	if !a.full() || a.alloc(board.NoMove, &pos) != nil {
+		t.Fatalf("expected the arena to be full")
+	}
+
+	a.prune(root)
+	if a.live != 1 || root.children != 0 || len(root.untriedMoves) != 20+2 {
+		t.Errorf("expected the children to be released and their moves untried again, got %d nodes %d children %d moves",
+			a.live, root.children, len(root.untriedMoves))
+	}
+	if n := a.alloc(board.NoMove, &pos); n == nil || a.used != 3 {
+		t.Errorf("expected a released node to be reused")
+	}
+}
+
+// TestTreeMemoryLimit checks that the tree never holds more nodes than its arena allows
+// and that the garbage collection keeps the statistics of the root
+func TestTreeMemoryLimit(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	cfg := DefaultConfig()
+	cfg.RolloutDepth = 2
+	tr := &tree{}
+	tr.reset(&pos, cfg, 100, 1, nil)
+	for i := 0; i < 1000; i++ {
+		tr.playout()
+		if tr.arena.live > tr.arena.capacity {
+			t.Fatalf("%d nodes exceed the capacity of %d", tr.arena.live, tr.arena.capacity)
+		}
+	}
+	if tr.root.visits != 1000 {
+		t.Errorf("expected 1000 root visits, got %.0f", tr.root.visits)
+	}
+	count := 0
+	tr.root.forEach(tr.arena, func(n *Node) { count++ })
+	if count != tr.arena.live {
+		t.Errorf("expected %d reachable nodes, got %d", tr.arena.live, count)
+	}
+}
+
+// BenchmarkPlayout measures a playout of the tree (the allocations per op are the
+// allocations per simulation)
+func BenchmarkPlayout(b *testing.B) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	cfg := DefaultConfig()
+	cfg.RolloutDepth = 8
+	tr := &tree{}
+	tr.reset(&pos, cfg, 4096, 1, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.playout()
+	}
+	reportPeakRSS(b)
+}
@@ -0,0 +1,44 @@
+package uct
+
+import (
+	"slinky/board"
+	"slinky/eval"
+)
+
+// setContempt sets the draw scores of both sides for a search from the given root
+// position. The side to move at the root is the engine: with a positive contempt a
+// draw scores below 0.5 for it and above 0.5 for its opponent, so the draw score of
+// a position is the same from both points of view (see Config.Contempt)
+func (cfg *Config) setContempt(state *board.ChessBoard) {
+	contempt := eval.Contempt(state, cfg.Contempt, cfg.ContemptPhase)
+	shift := WinProbability(-contempt, cfg.EvalScale) - 0.5
+	cfg.drawShift[state.Side] = shift
+	cfg.drawShift[state.Side^1] = -shift
+}
+
+// drawScore returns the score (0-1) of a draw for player
+func (cfg *Config) drawScore(player int) float64 {
+	return 0.5 + cfg.drawShift[player]
+}
+
+// resultScore converts the result of a game from the point of view of player to its
+// score (0-1). It is used for every finished game: the terminal nodes of the tree and
+// the ends of the rollouts
+func (cfg *Config) resultScore(result board.Result, player int) float64 {
+	if result == board.Draw {
+		return cfg.drawScore(player)
+	}
+	return float64(result)
+}
+
+// drawScore returns the score of a draw for the side to move at the root in the
+// current (or last) search
+func (s *Searcher) drawScore() float64 {
+	if len(s.trees) == 0 {
+		return 0.5
+	}
+	t := s.trees[0]
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg.drawScore(t.state.Side)
+}
@@ -0,0 +1,56 @@
+package uct
+
+import (
+	"context"
+	"math"
+	"slinky/board"
+	"testing"
+)
+
+// TestContemptDrawScore checks that a draw scores below 0.5 for the engine and above
+// 0.5 for its opponent, so both points of view agree
+func TestContemptDrawScore(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	cfg := DefaultConfig()
+	cfg.setContempt(&pos)
+	if draw := cfg.drawScore(board.White); draw != 0.5 {
+		t.Errorf("expected a draw score of 0.5 without contempt, got %f", draw)
+	}
+
+	cfg.Contempt = 50
+	cfg.setContempt(&pos)
+	engine, opponent := cfg.drawScore(board.White), cfg.drawScore(board.Black)
+	if engine >= 0.5 || math.Abs(engine+opponent-1) > 1e-9 {
+		t.Errorf("expected draw scores below and above 0.5 adding up to 1, got %f and %f", engine, opponent)
+	}
+	if score := cfg.resultScore(board.Draw, board.White); score != engine {
+		t.Errorf("expected the result score of a draw to be %f, got %f", engine, score)
+	}
+	if score := cfg.resultScore(board.Win, board.White); score != 1 {
+		t.Errorf("expected the result score of a win to be 1, got %f", score)
+	}
+}
+
+// TestContemptReporting checks that a proven draw is reported as minus the contempt
+func TestContemptReporting(t *testing.T) {
+	board.AllInit()
+	// every move leaves insufficient material on the board
+	pos := board.CreateBoard()
+	pos.ParseFen("8/8/8/4k3/8/8/8/4K2N w - - 0 1")
+
+	searcher := NewSearcher()
+	searcher.Config.Threads = 1
+	searcher.Config.Contempt = 50
+	result := searcher.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 500, Seed: 1})
+
+	if cp := Centipawns(result.Score, searcher.Config.EvalScale); cp != -50 {
+		t.Errorf("expected a score of -50 cp for a drawn position, got %d", cp)
+	}
+	lines := searcher.Progress(1).Lines
+	if len(lines) == 0 || lines[0].Cp != -50 || lines[0].WDL != [3]int{0, 1000, 0} {
+		t.Errorf("expected a proven draw reported as -50 cp, got %+v", lines)
+	}
+}
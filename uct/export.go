@@ -0,0 +1,162 @@
+package uct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slinky/board"
+	"sort"
+	"strings"
+)
+
+// TreeNode is an exported node of the search tree, merged from the trees of all
+// workers. The statistics are from the point of view of the side that played Move
+type TreeNode struct {
+	Move       string      `json:"move"` // move in long algebraic notation (empty for the root)
+	SAN        string      `json:"san"`  // move in standard algebraic notation
+	Visits     float64     `json:"visits"`
+	Wins       float64     `json:"wins"` // a draw counts as half a win
+	Draws      float64     `json:"draws"`
+	WinRate    float64     `json:"winRate"`
+	UCB        float64     `json:"ucb"`   // score of the node in the selection of its parent (see Node.childScore)
+	Proof      string      `json:"proof"` // proven result (see ProofNames), empty if unproven
+	ProofPlies int         `json:"proofPlies,omitempty"`
+	Children   []*TreeNode `json:"children,omitempty"` // most visited first
+
+	scores float64 // selection scores of the merged nodes weighted by their visits
+}
+
+// ProofNames maps the proof status of a node to its exported name
+var ProofNames = map[int]string{Unproven: "", ProvenWin: "win", ProvenLoss: "loss", ProvenDraw: "draw"}
+
+// ExportOptions limit the part of the tree that is exported
+type ExportOptions struct {
+	MaxDepth  int     // maximum depth below the root (0 - no limit)
+	MinVisits float64 // nodes with fewer visits are left out
+}
+
+// Tree exports the searched tree down to the limits of opts. The trees of all workers
+// are merged: nodes reached by the same moves are combined. It is safe to call while
+// a search is running. nil is returned if nothing was searched yet
+func (s *Searcher) Tree(opts ExportOptions) *TreeNode {
+	var root *TreeNode
+	for _, t := range s.trees {
+		t.mu.Lock()
+		if t.root != nil {
+			state := t.state
+			if root == nil {
+				root = &TreeNode{}
+			}
+			root.merge(t.arena, t.root, &state, &t.cfg, opts, 0)
+		}
+		t.mu.Unlock()
+	}
+	if root == nil {
+		return nil
+	}
+	root.finish(opts)
+	return root
+}
+
+// merge adds the statistics of node and its subtree. state is the position of node and
+// cfg the config of its tree
+func (tn *TreeNode) merge(a *arena, node *Node, state *board.ChessBoard, cfg *Config, opts ExportOptions, depth int) {
+	tn.Visits += node.visits
+	tn.Wins += node.wins
+	tn.Draws += node.draws
+	if node.proof != Unproven {
+		// a proof is valid in every tree
+		tn.Proof, tn.ProofPlies = ProofNames[node.proof], node.proofPlies
+	}
+	if opts.MaxDepth > 0 && depth == opts.MaxDepth {
+		return
+	}
+
+	for child := a.child(node); child != nil; child = a.sibling(child) {
+		move := board.PrintMove(child.move)
+		var exported *TreeNode
+		for _, c := range tn.Children {
+			if c.Move == move {
+				exported = c
+			}
+		}
+		if exported == nil {
+			exported = &TreeNode{Move: move, SAN: state.MoveToSAN(child.move)}
+			tn.Children = append(tn.Children, exported)
+		}
+		// the score the selection of this tree gives the child
+		if child.visits > 0 {
+			exported.scores += node.childScore(child, cfg) * child.visits
+		}
+		state.MakeMove(child.move)
+		exported.merge(a, child, state, cfg, opts, depth+1)
+		state.TakeMove()
+	}
+}
+
+// finish computes the win rates and the selection scores (the mean of the scores of
+// the merged nodes), removes the nodes with too few visits and sorts the children by
+// their visits
+func (tn *TreeNode) finish(opts ExportOptions) {
+	if tn.Visits > 0 {
+		tn.WinRate = tn.Wins / tn.Visits
+		tn.UCB = tn.scores / tn.Visits
+	}
+
+	children := tn.Children[:0]
+	for _, child := range tn.Children {
+		if child.Visits < opts.MinVisits || child.Visits == 0 {
+			continue
+		}
+		child.finish(opts)
+		children = append(children, child)
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Visits > children[j].Visits })
+	tn.Children = children
+}
+
+// WriteJSON writes the tree as indented JSON
+func WriteJSON(w io.Writer, root *TreeNode) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(root)
+}
+
+// proofColours fill colours of the proven nodes in the DOT output
+var proofColours = map[string]string{"win": "palegreen", "loss": "lightpink", "draw": "lightgrey"}
+
+// WriteDOT writes the tree in the Graphviz DOT language (i.e. render it with 'dot -Tsvg')
+func WriteDOT(w io.Writer, root *TreeNode) error {
+	var sb strings.Builder
+	sb.WriteString("digraph tree {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"monospace\", style=filled, fillcolor=white];\n")
+	id := 0
+	var write func(tn *TreeNode) int
+	write = func(tn *TreeNode) int {
+		nodeID := id
+		id++
+		name := tn.SAN
+		if name == "" {
+			name = "root"
+		}
+		label := fmt.Sprintf("%s\\nvisits %.0f\\nwins %.1f\\nwin rate %.3f", name, tn.Visits, tn.Wins, tn.WinRate)
+		if tn.Move != "" {
+			label += fmt.Sprintf("\\nucb %.3f", tn.UCB)
+		}
+		attributes := ""
+		if tn.Proof != "" {
+			label += fmt.Sprintf("\\nproven %s in %d", tn.Proof, tn.ProofPlies)
+			attributes = fmt.Sprintf(", fillcolor=%s", proofColours[tn.Proof])
+		}
+		fmt.Fprintf(&sb, "\tn%d [label=\"%s\"%s];\n", nodeID, label, attributes)
+		for _, child := range tn.Children {
+			fmt.Fprintf(&sb, "\tn%d -> n%d;\n", nodeID, write(child))
+		}
+		return nodeID
+	}
+	write(root)
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,83 @@
+package uct
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"slinky/board"
+	"strings"
+	"testing"
+)
+
+// TestTreeExport checks that the merged tree holds the statistics of the root moves
+// and that it is written as JSON and DOT
+func TestTreeExport(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+
+	s := NewSearcher()
+	s.Config.Threads = 2
+	s.Config.RolloutDepth = 4
+	s.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 100, Seed: 1})
+
+	root := s.Tree(ExportOptions{MaxDepth: 1})
+	if root == nil || len(root.Children) == 0 {
+		t.Fatalf("expected an exported tree")
+	}
+	visits := 0.0
+	for _, rootMove := range s.RootMoves() {
+		visits += rootMove.Visits
+	}
+	total := 0.0
+	var mate *TreeNode
+	for _, child := range root.Children {
+		total += child.Visits
+		if len(child.Children) > 0 {
+			t.Errorf("expected no nodes below depth 1, %s has %d children", child.SAN, len(child.Children))
+		}
+		if child.Move == "d1d8" {
+			mate = child
+		}
+	}
+	if total != visits {
+		t.Errorf("expected %.0f visits of the root moves, got %.0f", visits, total)
+	}
+	if mate == nil || mate.SAN != "Rd8#" || mate.Proof != "win" || mate.WinRate != 1 {
+		t.Errorf("expected the proven mate Rd8#, got %+v", mate)
+	}
+
+	// the exported selection scores are the ones used by the selection of the search
+	single := NewSearcher()
+	single.Config.Threads = 1
+	single.Config.RolloutDepth = 4
+	single.Config.Selection = PUCTSelectionName
+	single.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 100, Seed: 1})
+	t0 := single.trees[0]
+	for _, child := range single.Tree(ExportOptions{MaxDepth: 1}).Children {
+		for node := t0.arena.child(t0.root); node != nil; node = t0.arena.sibling(node) {
+			score := t0.root.childScore(node, &t0.cfg)
+			if board.PrintMove(node.move) == child.Move && math.Abs(child.UCB-score) > 1e-9 {
+				t.Errorf("%s: expected the selection score %f, got %f", child.SAN, score, child.UCB)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	var decoded TreeNode
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Children) != len(root.Children) {
+		t.Errorf("expected the JSON to decode to the same tree, got %v", err)
+	}
+
+	buf.Reset()
+	if err := WriteDOT(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	if dot := buf.String(); !strings.HasPrefix(dot, "digraph") || !strings.Contains(dot, "Rd8#") || !strings.Contains(dot, "proven win") {
+		t.Errorf("unexpected DOT output:\n%s", dot)
+	}
+}
@@ -0,0 +1,114 @@
+package uct
+
+import "slinky/board"
+
+// MCTS-minimax hybrids:
+//   - minimax in the rollouts: before every rollout move a 1 or 2 ply search looks for
+//     a mate or a winning capture and (2 ply) avoids moves that allow a mate in one
+//   - implicit minimax backups: every node keeps a minimax value computed from the
+//     static evaluation of the leaves, which is blended with the win rate in selection
+
+// tacticalWinGain minimum material (by SEE) a capture has to win to be played by the rollout minimax
+const tacticalWinGain = 300
+
+// isMate returns true if the side to move is checkmated
+func isMate(state *board.ChessBoard) bool {
+	return state.InCheck() && !state.HasLegalMove()
+}
+
+// givesMate returns true if the move checkmates the opponent
+func givesMate(state *board.ChessBoard, move int) bool {
+	state.MakeMove(move)
+	mate := isMate(state)
+	state.TakeMove()
+	return mate
+}
+
+// allowsMate returns true if the opponent has a mate in one after the move
+func allowsMate(state *board.ChessBoard, move int) bool {
+	state.MakeMove(move)
+	defer state.TakeMove()
+
+	var moveList board.MoveList
+	state.GenerateAllMoves(&moveList)
+	for i := 0; i < moveList.Count; i++ {
+		reply := moveList.Moves[i]
+		if state.IsMoveLegal(reply) && givesMate(state, reply) {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloutMinimax runs a shallow search on the moves of a rollout position. If a move
+// wins (mate in one or a capture winning at least tacticalWinGain) it is returned
+// together with true. Otherwise the moves worth considering are returned: with a
+// depth of 2 the moves that allow a mate in one are removed (unless all moves do).
+// The moves are reordered in place, so that no memory is allocated
+func rolloutMinimax(state *board.ChessBoard, moves []int, depth int) (int, bool, []int) {
+	bestCapture, bestGain := board.NoMove, tacticalWinGain-1
+	for _, move := range moves {
+		if givesMate(state, move) {
+			return move, true, moves
+		}
+		if MaterialGain(move) > bestGain {
+			if gain := SEE(state, move); gain > bestGain {
+				bestCapture, bestGain = move, gain
+			}
+		}
+	}
+	if bestCapture != board.NoMove {
+		return bestCapture, true, moves
+	}
+
+	if depth < 2 {
+		return board.NoMove, false, moves
+	}
+	safe := 0
+	for i, move := range moves {
+		if !allowsMate(state, move) {
+			moves[safe], moves[i] = moves[i], moves[safe]
+			safe++
+		}
+	}
+	if safe == 0 {
+		return board.NoMove, false, moves
+	}
+	return board.NoMove, false, moves[:safe]
+}
+
+// setMinimax sets the minimax value of a leaf node (from the point of view of playerJustMoved)
+func (n *Node) setMinimax(value float64) {
+	n.minimax = value
+	n.hasMinimax = true
+}
+
+// updateMinimax backs up the minimax value of the children: the side to move at the
+// node picks the child with the best value for itself. Proven children count with
+// their proven result
+func (n *Node) updateMinimax(a *arena, cfg *Config) {
+	best, found := 0.0, false
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		value, ok := child.minimaxValue(cfg)
+		if ok && (!found || value > best) {
+			best, found = value, true
+		}
+	}
+	if found {
+		n.setMinimax(1 - best)
+	}
+}
+
+// minimaxValue returns the minimax value of the node and false if it has none. A
+// proven draw is worth the draw score of playerJustMoved
+func (n *Node) minimaxValue(cfg *Config) (float64, bool) {
+	switch n.proof {
+	case ProvenWin:
+		return 1, true
+	case ProvenLoss:
+		return 0, true
+	case ProvenDraw:
+		return cfg.drawScore(n.playerJustMoved), true
+	}
+	return n.minimax, n.hasMinimax
+}
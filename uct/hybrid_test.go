@@ -0,0 +1,58 @@
+package uct
+
+import (
+	"slinky/board"
+	"testing"
+)
+
+// TestRolloutMinimax checks that the rollout minimax plays a mate in one and avoids moves that allow one
+func TestRolloutMinimax(t *testing.T) {
+	board.AllInit()
+
+	pos := board.CreateBoard()
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	move, found, _ := rolloutMinimax(&pos, pos.GetMoves(), 1)
+	if !found || board.PrintMove(move) != "d1d8" {
+		t.Errorf("expected the mate d1d8, got %s", board.PrintMove(move))
+	}
+
+	pos.ParseFen("3r2k1/5ppp/8/8/8/8/5PPP/6K1 w - - 0 1")
+	moves := pos.GetMoves()
+	if _, _, candidates := rolloutMinimax(&pos, moves, 1); len(candidates) != len(moves) {
+		t.Errorf("expected all moves with depth 1, got %d of %d", len(candidates), len(moves))
+	}
+	_, found, candidates := rolloutMinimax(&pos, moves, 2)
+	if found {
+		t.Errorf("unexpected winning move")
+	}
+	for _, move := range candidates {
+		if board.PrintMove(move) == "g1h1" {
+			t.Errorf("expected g1h1 to be removed since it allows d8d1 mate")
+		}
+	}
+	if len(candidates) != len(moves)-1 {
+		t.Errorf("expected only g1h1 to be removed, got %d of %d moves", len(candidates), len(moves))
+	}
+}
+
+// TestImplicitMinimax checks that the minimax value is backed up from the side to move's point of view
+func TestImplicitMinimax(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	cfg := DefaultConfig()
+	a := newArena(4)
+	parent := createRootNode(a, &pos)
+	for _, minimax := range []float64{0.3, 0.8, -1} {
+		child := a.alloc(board.NoMove, &pos)
+		if minimax >= 0 {
+			child.setMinimax(minimax)
+		}
+		a.link(parent, child)
+	}
+	parent.updateMinimax(a, &cfg)
+	if value, ok := parent.minimaxValue(&cfg); !ok || value < 0.19 || value > 0.21 {
+		t.Errorf("expected a minimax value of 0.2, got %f (%t)", value, ok)
+	}
+}
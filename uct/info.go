@@ -0,0 +1,157 @@
+package uct
+
+import (
+	"math"
+	"sort"
+)
+
+// Line is a principal variation starting with one of the root moves
+type Line struct {
+	Move   int
+	Wins   float64 // wins from the point of view of the side to move at the root
+	Visits float64
+	Cp     int    // expected score converted to centipawns (see Centipawns)
+	WDL    [3]int // win, draw and loss probability in permille
+	Mate   int    // moves until mate (negative if the side to move gets mated), 0 if no mate was found
+	PV     []int  // most visited path starting with Move
+}
+
+// Progress is a snapshot of a (possibly still running) search
+type Progress struct {
+	Depth       int     // mean tree depth reached by the playouts
+	SelDepth    int     // deepest tree depth reached by a playout
+	Simulations int     // number of playouts done in this search
+	Nodes       int     // number of nodes in all trees
+	HashFull    int     // usage of the transposition tables (or of the node arenas if disabled) in permille
+	Lines       []Line  // best root moves (best first)
+	Selection   string  // move selection policy used to order the root moves
+	Margin      float64 // how much better the best move is than the runner-up (see selectionMargin)
+}
+
+// principalVariation returns the most visited path starting with the root move,
+// taken from the tree that visited the root move the most
+func (s *Searcher) principalVariation(move int) []int {
+	pv := make([]int, 0)
+	bestVisits := 0.0
+	for _, t := range s.trees {
+		t.mu.Lock()
+		if t.root != nil {
+			for child := t.arena.child(t.root); child != nil; child = t.arena.sibling(child) {
+				if child.move == move && child.visits > bestVisits {
+					bestVisits = child.visits
+					pv = child.principalVariation(t.arena, s.Config.MoveSelection)
+				}
+			}
+		}
+		t.mu.Unlock()
+	}
+	return pv
+}
+
+// principalVariation follows the best children starting from the node
+func (n *Node) principalVariation(a *arena, selection string) []int {
+	pv := []int{n.move}
+	for node := n.bestChild(a, selection); node != nil && node.visits > 0; node = node.bestChild(a, selection) {
+		pv = append(pv, node.move)
+	}
+	return pv
+}
+
+// Progress returns a snapshot of the search containing the multiPV best root moves.
+// It is safe to call while a search is running
+func (s *Searcher) Progress(multiPV int) Progress {
+	var progress Progress
+	depthSum := 0
+	hashFull, tables, capacity := 0, 0, 0
+	for _, t := range s.trees {
+		t.mu.Lock()
+		progress.Simulations += t.simulations
+		if t.arena != nil {
+			progress.Nodes += t.arena.live
+			capacity += t.arena.capacity
+		}
+		depthSum += t.depthSum
+		if t.selDepth > progress.SelDepth {
+			progress.SelDepth = t.selDepth
+		}
+		if t.tt != nil {
+			hashFull += t.tt.hashFull()
+			tables++
+		}
+		t.mu.Unlock()
+	}
+
+	if progress.Simulations > 0 {
+		progress.Depth = (depthSum + progress.Simulations/2) / progress.Simulations
+	}
+	if tables > 0 {
+		progress.HashFull = hashFull / tables
+	} else if capacity > 0 {
+		progress.HashFull = progress.Nodes * 1000 / capacity
+	}
+
+	selection := s.Config.MoveSelection
+	rootMoves := s.RootMoves()
+	sort.SliceStable(rootMoves, func(i, j int) bool {
+		return rootMoveBefore(rootMoves[i], rootMoves[j], selection)
+	})
+
+	progress.Selection = selection
+	if len(rootMoves) > 1 {
+		totalVisits := 0.0
+		for _, rootMove := range rootMoves {
+			totalVisits += rootMove.Visits
+		}
+		progress.Margin = selectionMargin(rootMoves[0], rootMoves[1], totalVisits, selection)
+	}
+
+	if multiPV < len(rootMoves) {
+		rootMoves = rootMoves[:multiPV]
+	}
+
+	draw := s.drawScore()
+	for _, rootMove := range rootMoves {
+		line := Line{
+			Move:   rootMove.Move,
+			Wins:   rootMove.Wins,
+			Visits: rootMove.Visits,
+			PV:     s.principalVariation(rootMove.Move),
+		}
+		line.Mate = MateScore(rootMove.Proof, rootMove.ProofPlies)
+		line.WDL = rootMove.WDL()
+		line.Cp = Centipawns(rootMove.Score(draw), s.Config.EvalScale)
+		progress.Lines = append(progress.Lines, line)
+	}
+	return progress
+}
+
+// Score returns the expected score (0-1) of the root move from the point of view of
+// the side to move. draw is the score of a draw for the side to move
+func (r RootMove) Score(draw float64) float64 {
+	switch {
+	case r.Proof == ProvenWin:
+		return 1
+	case r.Proof == ProvenLoss:
+		return 0
+	case r.Proof == ProvenDraw:
+		return draw
+	case r.Visits == 0:
+		return 0.5
+	}
+	return r.Wins / r.Visits
+}
+
+// WDL returns the win, draw and loss probability of the root move in permille
+func (r RootMove) WDL() [3]int {
+	switch {
+	case r.Proof == ProvenWin:
+		return [3]int{1000, 0, 0}
+	case r.Proof == ProvenLoss:
+		return [3]int{0, 0, 1000}
+	case r.Proof == ProvenDraw || r.Visits == 0:
+		return [3]int{0, 1000, 0}
+	}
+	win := int(math.Round(1000 * r.DecisiveWins / r.Visits))
+	draw := int(math.Round(1000 * r.Draws / r.Visits))
+	return [3]int{win, draw, 1000 - win - draw}
+}
@@ -0,0 +1,91 @@
+package uct
+
+import (
+	"context"
+	"slinky/board"
+	"testing"
+)
+
+// TestCentipawns checks that the conversion is monotonic, the inverse of WinProbability
+// and positive when the side to move is better
+func TestCentipawns(t *testing.T) {
+	last := -maxCentipawns - 1
+	for score := 0.0; score <= 1; score += 0.01 {
+		cp := Centipawns(score, 400)
+		if cp < last {
+			t.Errorf("not monotonic: %.2f -> %d after %d", score, cp, last)
+		}
+		last = cp
+	}
+	if cp := Centipawns(0.5, 400); cp != 0 {
+		t.Errorf("expected 0 for an even score, got %d", cp)
+	}
+	if cp := Centipawns(0.7, 400); cp <= 0 {
+		t.Errorf("expected a positive score when the side to move is better, got %d", cp)
+	}
+	for _, cp := range []int{-300, -50, 0, 120, 800} {
+		if got := Centipawns(WinProbability(cp, 400), 400); got != cp {
+			t.Errorf("expected %d after converting back and forth, got %d", cp, got)
+		}
+	}
+}
+
+// TestRootMoveWDL checks that draws are counted separately from wins and losses
+func TestRootMoveWDL(t *testing.T) {
+	// 50 wins, 30 draws and 20 losses with a draw score of 0.4
+	rootMove := RootMove{Wins: 62, DecisiveWins: 50, Draws: 30, Visits: 100}
+	if wdl := rootMove.WDL(); wdl != [3]int{500, 300, 200} {
+		t.Errorf("expected wdl 500 300 200, got %v", wdl)
+	}
+	rootMove.Proof = ProvenLoss
+	if wdl := rootMove.WDL(); wdl != [3]int{0, 0, 1000} {
+		t.Errorf("expected wdl 0 0 1000 for a proven loss, got %v", wdl)
+	}
+
+	// the draw score does not leak into the win probability
+	var n Node
+	n.Update(1, false)
+	n.Update(0.4, true)
+	n.Update(0.4, true)
+	n.Update(0, false)
+	if wdl := n.rootMove().WDL(); wdl != [3]int{250, 500, 250} {
+		t.Errorf("expected wdl 250 500 250, got %v", wdl)
+	}
+}
+
+// TestProgressLines checks that the progress holds the multiPV best root moves in the
+// order of the move selection policy, each with a principal variation starting with it
+func TestProgressLines(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	for _, selection := range []string{RobustChildName, MaxChildName} {
+		s := NewSearcher()
+		s.Config.Threads = 2
+		s.Config.RolloutDepth = 8
+		s.Config.MoveSelection = selection
+		s.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 400, Seed: 3})
+
+		progress := s.Progress(3)
+		if progress.Simulations != 400 || progress.Nodes == 0 || progress.Depth < 1 || progress.SelDepth < progress.Depth {
+			t.Errorf("%s: unexpected progress %+v", selection, progress)
+		}
+		if len(progress.Lines) != 3 || progress.Selection != selection {
+			t.Fatalf("%s: expected 3 lines ordered by %s, got %d by %s", selection, selection, len(progress.Lines), progress.Selection)
+		}
+		for i, line := range progress.Lines {
+			if len(line.PV) == 0 || line.PV[0] != line.Move {
+				t.Errorf("%s: line %d has the PV %v for the move %s", selection, i+1, line.PV, board.PrintMove(line.Move))
+			}
+			if i == 0 {
+				continue
+			}
+			previous := RootMove{Wins: progress.Lines[i-1].Wins, Visits: progress.Lines[i-1].Visits}
+			current := RootMove{Wins: line.Wins, Visits: line.Visits}
+			if selectionScore(current, selection) > selectionScore(previous, selection) {
+				t.Errorf("%s: line %d scores higher than line %d", selection, i+1, i)
+			}
+		}
+	}
+}
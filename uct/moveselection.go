@@ -0,0 +1,91 @@
+package uct
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Names of the policies that choose the move to play once the search is over
+const (
+	MaxChildName       = "max-child"    // the move with the best mean result
+	RobustChildName    = "robust-child" // the move with the most visits
+	MaxRobustChildName = "max-robust"   // search until the max and the robust child agree, then play it
+	SecureChildName    = "secure-child" // the move with the best lower confidence bound
+)
+
+// MoveSelectionNames lists the names of all move selection policies
+var MoveSelectionNames = []string{MaxChildName, RobustChildName, MaxRobustChildName, SecureChildName}
+
+// secureChildConfidence A in the lower confidence bound mean - A/sqrt(visits) of the secure child
+const secureChildConfidence = 1.0
+
+// MaxRobustExtension part of the allotted time the search may be extended by until the
+// max and the robust child agree
+const MaxRobustExtension = 0.5
+
+// maxRobustCheckInterval time between the checks whether the max and the robust child agree
+const maxRobustCheckInterval = 10 * time.Millisecond
+
+// selectionScore returns the score of an unproven root move according to the move
+// selection policy (higher is better)
+func selectionScore(rootMove RootMove, selection string) float64 {
+	if rootMove.Visits == 0 {
+		return math.Inf(-1)
+	}
+	switch selection {
+	case MaxChildName:
+		return rootMove.Wins / rootMove.Visits
+	case SecureChildName:
+		return rootMove.Wins/rootMove.Visits - secureChildConfidence/math.Sqrt(rootMove.Visits)
+	default:
+		return rootMove.Visits
+	}
+}
+
+// selectionMargin returns how much better the best move is than the runner-up according
+// to the selection policy. The difference of visits is given as a share of all visits so
+// that the margin of every policy is a fraction
+func selectionMargin(best, runnerUp RootMove, totalVisits float64, selection string) float64 {
+	if best.Proof != Unproven || runnerUp.Proof != Unproven {
+		return 0
+	}
+	margin := selectionScore(best, selection) - selectionScore(runnerUp, selection)
+	if selection == RobustChildName || selection == MaxRobustChildName {
+		margin /= totalVisits
+	}
+	return margin
+}
+
+// MaxRobustAgree returns true if the move with the best mean result also has the most visits.
+// It is safe to call while a search is running
+func (s *Searcher) MaxRobustAgree() bool {
+	rootMoves := s.RootMoves()
+	maxChild := bestRootMove(rootMoves, MaxChildName)
+	robustChild := bestRootMove(rootMoves, RobustChildName)
+	return maxChild.Move == robustChild.Move
+}
+
+// stopWhenMaxRobust stops the search once the soft stop time is reached and the max and
+// the robust child agree (the hard limit is the deadline of ctx)
+func (s *Searcher) stopWhenMaxRobust(ctx context.Context, stop context.CancelFunc, softStop time.Time) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Until(softStop)):
+	}
+
+	ticker := time.NewTicker(maxRobustCheckInterval)
+	defer ticker.Stop()
+	for {
+		if s.MaxRobustAgree() {
+			stop()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
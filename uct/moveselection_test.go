@@ -0,0 +1,32 @@
+package uct
+
+import "testing"
+
+// TestMoveSelection checks which root move each move selection policy picks
+func TestMoveSelection(t *testing.T) {
+	rootMoves := []RootMove{
+		{Move: 1, Wins: 60, Visits: 100}, // most visits
+		{Move: 2, Wins: 5, Visits: 6},    // best mean, but hardly searched
+		{Move: 3, Wins: 55, Visits: 80},  // best lower confidence bound
+	}
+
+	tests := map[string]int{
+		MaxChildName:       2,
+		RobustChildName:    1,
+		MaxRobustChildName: 1,
+		SecureChildName:    3,
+	}
+	for selection, expected := range tests {
+		if best := bestRootMove(rootMoves, selection); best.Move != expected {
+			t.Errorf("%s: expected move %d, got %d", selection, expected, best.Move)
+		}
+	}
+
+	// a proven win is always played
+	proven := append(rootMoves, RootMove{Move: 4, Wins: 0, Visits: 1, Proof: ProvenWin})
+	for selection := range tests {
+		if best := bestRootMove(proven, selection); best.Move != 4 {
+			t.Errorf("%s: expected the proven win, got %d", selection, best.Move)
+		}
+	}
+}
@@ -6,34 +6,64 @@ import (
 	"slinky/board"
 )
 
-// Node structure to hold information about each node in the MCTS
+// Node structure to hold information about each node in the MCTS. The nodes live
+// in the arena of their tree and are linked by index (see arena.go)
 type Node struct {
 	move            int
-	parent          *Node
-	childNodes      []*Node
-	wins            float64
+	index           nodeIndex
+	parent          nodeIndex
+	child           nodeIndex // first child
+	sibling         nodeIndex // next child of the parent
+	children        int       // number of children
+	wins            float64   // sum of the results (a draw counts with the draw score)
+	decisiveWins    float64   // sum of the results of the playouts that did not end in a draw
+	draws           float64   // number of playouts that ended in a draw
 	visits          float64
 	untriedMoves    []int
 	playerJustMoved int
+	proof           int // proof status from the point of view of playerJustMoved (see solver.go)
+	proofPlies      int // number of plies until the game ends in the proven result
+	posKey          uint64
+	entry           *ttEntry  // shared statistics of the position (nil if the transposition table is disabled)
+	prior           float64   // prior probability of the move (only used by PUCT)
+	priors          []float64 // prior probabilities of the untried moves (nil until computed)
+	amafWins        float64   // all-moves-as-first statistics used by RAVE (see rave.go)
+	amafVisits      float64
+	ordered         bool    // the untried moves are sorted by the move ordering (see orderMoves)
+	minimax         float64 // implicit minimax value from the point of view of playerJustMoved (see hybrid.go)
+	hasMinimax      bool
 }
 
-// Update result of game to this node (backpropagate)
-func (n *Node) Update(gameResult float64) {
+// Names of the selection strategies
+const (
+	UCB1SelectionName = "ucb1"
+	PUCTSelectionName = "puct"
+)
+
+// SelectionNames lists the names of all selection strategies
+var SelectionNames = []string{UCB1SelectionName, PUCTSelectionName}
+
+// Update result of game to this node (backpropagate). draw is true if the
+// game ended in a draw (and not if the result is an evaluation of 0.5), the
+// result of a draw is the draw score of the player (see Config.Contempt)
+func (n *Node) Update(gameResult float64, draw bool) {
 	n.visits += 1.0
 	n.wins += gameResult
+	if draw {
+		n.draws++
+	} else {
+		n.decisiveWins += gameResult
+	}
 }
 
-// AddChild adds child node from a given untried move under this node
-// note state here can be a pointer to struct
-func (n *Node) AddChild(move int, state *board.ChessBoard) *Node {
-	node := Node{
-		move:            move,
-		parent:          n,
-		untriedMoves:    state.GetMoves(),
-		playerJustMoved: state.GetPlayerJustMoved(),
+// addChild adds child node from a given untried move under this node. state is the
+// position after the move. nil is returned if the arena is full
+func (n *Node) addChild(a *arena, move int, state *board.ChessBoard) *Node {
+	node := a.alloc(move, state)
+	if node == nil {
+		return nil
 	}
-
-	n.childNodes = append(n.childNodes, &node)
+	a.link(n, node)
 
 	moveIdx := -1
 	for idx, m := range n.untriedMoves {
@@ -46,6 +76,13 @@ func (n *Node) AddChild(move int, state *board.ChessBoard) *Node {
 		panic(fmt.Sprintf("Couldn't find move in untried moves %d", move))
 	}
 
+	if n.priors != nil {
+		node.prior = n.priors[moveIdx]
+		lastPriorIdx := len(n.priors) - 1
+		n.priors[moveIdx] = n.priors[lastPriorIdx]
+		n.priors = n.priors[:lastPriorIdx]
+	}
+
 	// delete move from untried moves
 	// Most efficient way to do this is to swap
 	// Unwanted element with the last element
@@ -54,37 +91,106 @@ func (n *Node) AddChild(move int, state *board.ChessBoard) *Node {
 	n.untriedMoves[moveIdx] = n.untriedMoves[lastElementIdx]
 	n.untriedMoves = n.untriedMoves[:lastElementIdx]
 
-	return &node
+	return node
 }
 
-func (n *Node) ucb1(node *Node) float64 {
-	return (node.wins / node.visits) +
-		math.Sqrt(2*math.Log(n.visits)/node.visits)
+// setPriors computes the priors of the untried moves. The untried moves are sorted
+// by their prior (best last), so the next move to expand is always the last one.
+// This order is kept by AddChild since it swaps the expanded move with the last one
+func (n *Node) setPriors(state *board.ChessBoard, prior Prior, history *History) {
+	priors := prior.Priors(state, n.untriedMoves, history)
+	sortByScore(n.untriedMoves, priors)
+	n.priors = priors
 }
 
-// SelectChild Evaluate all of the node's children using UCB1 formula
-// and return most promising one
-func (n *Node) SelectChild() *Node {
-	bestChildIdx := 0
+// ucb1 returns the UCB1 score of a child with the given value and visits
+func (n *Node) ucb1(value, visits, exploration float64) float64 {
+	return value +
+		exploration*math.Sqrt(math.Log(n.visits)/visits)
+}
+
+// puct returns the AlphaZero style score of a child: its value plus an exploration
+// term that is proportional to the prior of the move
+func (n *Node) puct(value, prior, visits, exploration float64) float64 {
+	return value + exploration*prior*math.Sqrt(n.visits)/(1+visits)
+}
+
+// firstPlayUrgency returns the value assumed for the moves that were not tried yet:
+// the value of the node for the side to move reduced by fpuReduction
+func (n *Node) firstPlayUrgency(fpuReduction float64) float64 {
+	return 1 - n.wins/n.visits - fpuReduction
+}
+
+// childScore returns the score of a child in the selection of the node (see selectChild).
+// The value of the child is taken from the statistics of its position (see stats) while
+// the exploration term uses the visits of the child node itself
+func (n *Node) childScore(child *Node, cfg *Config) float64 {
+	if cfg.Selection == PUCTSelectionName {
+		return n.puct(child.value(cfg), child.prior, child.visits, cfg.Exploration)
+	}
+	return n.ucb1(child.value(cfg), child.visits, cfg.Exploration)
+}
+
+// selectChild evaluates all of the node's children using the selection strategy
+// of the config and returns the most promising one. Children with a proven result
+// are skipped. nil is returned if the node should be expanded instead (UCB1 tries
+// every move once, PUCT expands the best untried move once it scores higher than
+// all children) or if all children are proven. With progressive widening a node
+// is only expanded while it has fewer children than allowed by its visits
+func (n *Node) selectChild(a *arena, cfg *Config) *Node {
+	puct := cfg.Selection == PUCTSelectionName
+	expandable := len(n.untriedMoves) > 0 &&
+		(!cfg.Widening || n.children < cfg.wideningLimit(n.visits))
+	if expandable && (!puct || n.visits == 0) {
+		return nil
+	}
+
+	var bestChild *Node
 	bestChildScore := 0.0
+	if expandable {
+		// the untried moves are sorted by prior, the last one scores the highest
+		bestChildScore = n.puct(n.firstPlayUrgency(cfg.FPUReduction), n.priors[len(n.priors)-1], 0, cfg.Exploration)
+	}
 
-	for idx, child := range n.childNodes {
-		childScore := n.ucb1(child)
-		if childScore > bestChildScore {
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		if child.proof != Unproven {
+			continue
+		}
+		childScore := n.childScore(child, cfg)
+		if (bestChild == nil && !expandable) || childScore > bestChildScore {
 			bestChildScore = childScore
-			bestChildIdx = idx
+			bestChild = child
 		}
 	}
+	return bestChild
+}
 
-	return n.childNodes[bestChildIdx]
+// bestChild returns the best child according to the move selection policy (see rootMoveBefore)
+// or nil if the node has no children
+func (n *Node) bestChild(a *arena, selection string) *Node {
+	var bestChild *Node
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		if bestChild == nil || rootMoveBefore(child.rootMove(), bestChild.rootMove(), selection) {
+			bestChild = child
+		}
+	}
+	return bestChild
+}
+
+// rootMove returns the statistics of the node as if it was a root move
+func (n *Node) rootMove() RootMove {
+	return RootMove{Move: n.move, Wins: n.wins, DecisiveWins: n.decisiveWins, Draws: n.draws, Visits: n.visits, Proof: n.proof, ProofPlies: n.proofPlies}
 }
 
-// CreateRootNode creates a root node for a given board state
-func CreateRootNode(state *board.ChessBoard) Node {
-	return Node{
-		move:            -1, // this is set to an invalid move
-		parent:          nil,
-		untriedMoves:    state.GetMoves(),
-		playerJustMoved: state.GetPlayerJustMoved(),
+// forEach calls f for every node in the subtree of the node (including the node)
+func (n *Node) forEach(a *arena, f func(n *Node)) {
+	f(n)
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		child.forEach(a, f)
 	}
 }
+
+// createRootNode creates a root node for a given board state
+func createRootNode(a *arena, state *board.ChessBoard) *Node {
+	return a.alloc(-1, state) // the move of the root is set to an invalid move
+}
@@ -0,0 +1,177 @@
+package uct
+
+import (
+	"math"
+	"slinky/board"
+	"sort"
+)
+
+// maxKillerDepth number of tree depths for which killer moves are kept
+const maxKillerDepth = 64
+
+// Killers holds the two most recent killer moves of every tree depth. In the tree
+// a killer is a move that was proven to win, so the same move is tried early in
+// the sibling positions at the same depth
+type Killers [maxKillerDepth][2]int
+
+// Add stores a killer move of the given depth
+func (k *Killers) Add(depth, move int) {
+	if depth >= maxKillerDepth || k[depth][0] == move {
+		return
+	}
+	k[depth][1] = k[depth][0]
+	k[depth][0] = move
+}
+
+// IsKiller returns true if the move is a killer of the given depth
+func (k *Killers) IsKiller(depth, move int) bool {
+	return depth < maxKillerDepth && (k[depth][0] == move || k[depth][1] == move)
+}
+
+// orderMoves sorts the untried moves of the node from the worst to the best (see
+// moveOrderScore), so the next move to expand is always the last one
+func (n *Node) orderMoves(state *board.ChessBoard, depth int, killers *Killers, history *History) {
+	scores := make([]float64, len(n.untriedMoves))
+	for i, move := range n.untriedMoves {
+		scores[i] = moveOrderScore(state, move, killers.IsKiller(depth, move), history)
+	}
+	sortByScore(n.untriedMoves, scores)
+	n.ordered = true
+}
+
+// moveOrderScore scores a move for the move ordering: winning and equal captures
+// (by SEE) first, then checks, then killers. Moves of the same kind are ordered
+// by their history score
+func moveOrderScore(state *board.ChessBoard, move int, killer bool, history *History) float64 {
+	score := history.Score(state.Side, move) // between 0 and 1
+	if MaterialGain(move) > 0 {
+		if see := SEE(state, move); see >= 0 {
+			score += 4 + float64(see)/float64(board.PieceValue[board.WhiteKing])
+		}
+	}
+	if GivesCheck(state, move) {
+		score += 2
+	}
+	if killer {
+		score++
+	}
+	return score
+}
+
+// SEE returns the static exchange evaluation of a move: the material (in centipawns)
+// won by the side to move if both sides keep capturing on the target square with
+// their least valuable piece, each side being free to stop capturing
+func SEE(state *board.ChessBoard, move int) int {
+	pieces := state.Pieces // copy, the pieces taking part in the exchange are removed from it
+	from, to := board.FromSq(move), board.ToSq(move)
+
+	var gain [32]int
+	gain[0] = MaterialGain(move)
+	onTarget := board.PieceValue[pieces[from]]
+	if promoted := board.Promoted(move); promoted != board.Empty {
+		onTarget = board.PieceValue[promoted]
+	}
+	pieces[from] = board.Empty
+	if move&board.MoveFlagEnPass != 0 {
+		// the captured pawn is behind the target square
+		if state.Side == board.White {
+			pieces[to-10] = board.Empty
+		} else {
+			pieces[to+10] = board.Empty
+		}
+	}
+
+	side := state.Side ^ 1
+	depth := 0
+	for depth+1 < len(gain) {
+		sq := leastValuableAttacker(&pieces, to, side)
+		if sq == board.NoSquare {
+			break
+		}
+		depth++
+		gain[depth] = onTarget - gain[depth-1]
+		onTarget = board.PieceValue[pieces[sq]]
+		pieces[sq] = board.Empty
+		side ^= 1
+	}
+
+	// every side can stop capturing if continuing loses material
+	for ; depth > 0; depth-- {
+		if gain[depth] > -gain[depth-1] {
+			gain[depth-1] = -gain[depth]
+		}
+	}
+	return gain[0]
+}
+
+// leastValuableAttacker returns the square of the least valuable piece of side that
+// attacks sq, board.NoSquare if there is none
+func leastValuableAttacker(pieces *[board.BoardSquareNum]int, sq, side int) int {
+	best, bestValue := board.NoSquare, 0
+	consider := func(from int) {
+		if value := board.PieceValue[pieces[from]]; best == board.NoSquare || value < bestValue {
+			best, bestValue = from, value
+		}
+	}
+
+	pawn, pawnDir := board.WhitePawn, -10
+	if side == board.Black {
+		pawn, pawnDir = board.BlackPawn, 10
+	}
+	for _, from := range []int{sq + pawnDir - 1, sq + pawnDir + 1} {
+		if pieces[from] == pawn {
+			return from // nothing is less valuable than a pawn
+		}
+	}
+
+	for _, dir := range board.PieceDir[board.WhiteKnight] {
+		if piece := pieces[sq+dir]; piece != board.OffBoard && board.IsPieceKnight[piece] && board.PieceColour[piece] == side {
+			consider(sq + dir)
+		}
+	}
+	for _, dir := range board.PieceDir[board.WhiteKing] {
+		if piece := pieces[sq+dir]; piece != board.OffBoard && board.IsPieceKing[piece] && board.PieceColour[piece] == side {
+			consider(sq + dir)
+		}
+	}
+
+	for _, dir := range board.PieceDir[board.WhiteQueen] {
+		from := sq + dir
+		for pieces[from] == board.Empty {
+			from += dir
+		}
+		piece := pieces[from]
+		if piece == board.OffBoard || board.PieceColour[piece] != side {
+			continue
+		}
+		diagonal := dir == 9 || dir == -9 || dir == 11 || dir == -11
+		if (diagonal && board.IsPieceBishopQueen[piece]) || (!diagonal && board.IsPieceRookQueen[piece]) {
+			consider(from)
+		}
+	}
+	return best
+}
+
+// sortByScore sorts the moves by their score (lowest score first)
+func sortByScore(moves []int, scores []float64) {
+	sort.Sort(byScore{moves: moves, scores: scores})
+}
+
+// byScore sorts moves together with their scores
+type byScore struct {
+	moves  []int
+	scores []float64
+}
+
+func (b byScore) Len() int           { return len(b.moves) }
+func (b byScore) Less(i, j int) bool { return b.scores[i] < b.scores[j] }
+func (b byScore) Swap(i, j int) {
+	b.moves[i], b.moves[j] = b.moves[j], b.moves[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
+
+// wideningLimit returns the number of children a node with the given visits may have
+// with progressive widening: ceil(WideningFactor * visits^WideningExponent)
+func (cfg *Config) wideningLimit(visits float64) int {
+	return int(math.Ceil(cfg.WideningFactor * math.Pow(math.Max(visits, 1), cfg.WideningExponent)))
+}
@@ -0,0 +1,34 @@
+package uct
+
+import (
+	"slinky/board"
+	"testing"
+)
+
+// TestSEE checks the static exchange evaluation of a few captures
+func TestSEE(t *testing.T) {
+	board.AllInit()
+
+	tests := []struct {
+		fen  string
+		move string
+		see  int
+	}{
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", 100},      // undefended pawn
+		{"4k3/8/2p5/3p4/8/8/8/3RK3 w - - 0 1", "d1d5", -450},    // pawn defended by a pawn
+		{"3rk3/8/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 100},    // x-ray: the second rook backs up the first
+		{"3rk3/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", -450}, // both sides have two rooks
+		{"4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1", "d5e6", 100},      // en passant
+		{"r3k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", 550},        // undefended rook
+		{"rk6/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", 0},           // the king recaptures
+	}
+
+	for _, test := range tests {
+		pos := board.CreateBoard()
+		pos.ParseFen(test.fen)
+		move := pos.ParseMove(test.move)
+		if see := SEE(&pos, move); see != test.see {
+			t.Errorf("%s %s: expected SEE %d, got %d", test.fen, test.move, test.see, see)
+		}
+	}
+}
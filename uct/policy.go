@@ -0,0 +1,164 @@
+package uct
+
+import (
+	"math/rand"
+	"slinky/board"
+)
+
+// RolloutPolicy chooses the moves that are played during the rollout phase of a playout
+type RolloutPolicy interface {
+	// SelectMove returns one of the legal moves of the position. All random choices
+	// are made with rng, so that a seeded search can be reproduced
+	SelectMove(state *board.ChessBoard, moves []int, rng *rand.Rand) int
+}
+
+// Names of the available rollout policies
+const (
+	UniformPolicyName  = "uniform"
+	CapturePolicyName  = "mvvlva"
+	TacticalPolicyName = "tactical"
+	GreedyPolicyName   = "greedy"
+)
+
+// PolicyNames lists the names of all rollout policies
+var PolicyNames = []string{UniformPolicyName, CapturePolicyName, TacticalPolicyName, GreedyPolicyName}
+
+// NewRolloutPolicy returns the rollout policy with the given name.
+// Unknown names return the uniform policy
+func NewRolloutPolicy(name string) RolloutPolicy {
+	switch name {
+	case CapturePolicyName:
+		return CapturePolicy{}
+	case TacticalPolicyName:
+		return TacticalPolicy{CheckWeight: 8, PromotionWeight: 16}
+	case GreedyPolicyName:
+		return GreedyPolicy{Epsilon: 0.25}
+	default:
+		return UniformPolicy{}
+	}
+}
+
+// UniformPolicy picks every legal move with the same probability
+type UniformPolicy struct{}
+
+// SelectMove picks a random move
+func (p UniformPolicy) SelectMove(state *board.ChessBoard, moves []int, rng *rand.Rand) int {
+	return moves[rng.Intn(len(moves))]
+}
+
+// MvvLva returns the most valuable victim - least valuable attacker score of a move.
+// Quiet moves score 0, captures score between 1 (king takes pawn) and 30 (pawn takes queen)
+func MvvLva(state *board.ChessBoard, move int) int {
+	victim := board.Captured(move)
+	if move&board.MoveFlagEnPass != 0 {
+		victim = board.WhitePawn
+	}
+	if victim == board.Empty {
+		return 0
+	}
+	attacker := state.Pieces[board.FromSq(move)]
+	return board.MvvLvaRank[victim]*6 - board.MvvLvaRank[attacker] + 1
+}
+
+// CapturePolicy prefers captures. Each capture is weighted by its MVV-LVA score
+// while quiet moves have a weight of 1
+type CapturePolicy struct{}
+
+// SelectMove picks a random move, biased towards good captures
+func (p CapturePolicy) SelectMove(state *board.ChessBoard, moves []int, rng *rand.Rand) int {
+	return weightedMove(moves, rng, func(move int) float64 {
+		return float64(1 + MvvLva(state, move))
+	})
+}
+
+// TacticalPolicy prefers queen promotions and checking moves
+type TacticalPolicy struct {
+	CheckWeight     float64 // weight of a checking move (quiet moves have a weight of 1)
+	PromotionWeight float64 // weight of a queen promotion
+}
+
+// SelectMove picks a random move, biased towards promotions and checks
+func (p TacticalPolicy) SelectMove(state *board.ChessBoard, moves []int, rng *rand.Rand) int {
+	return weightedMove(moves, rng, func(move int) float64 {
+		weight := 1.0
+		if promoted := board.Promoted(move); promoted == board.WhiteQueen || promoted == board.BlackQueen {
+			weight += p.PromotionWeight
+		}
+		if GivesCheck(state, move) {
+			weight += p.CheckWeight
+		}
+		return weight
+	})
+}
+
+// GreedyPolicy plays the move with the best one-ply material gain, except with
+// probability Epsilon when it plays a random move
+type GreedyPolicy struct {
+	Epsilon float64
+}
+
+// SelectMove picks the move which wins the most material (ties are broken randomly)
+func (p GreedyPolicy) SelectMove(state *board.ChessBoard, moves []int, rng *rand.Rand) int {
+	if rng.Float64() < p.Epsilon {
+		return moves[rng.Intn(len(moves))]
+	}
+
+	bestGain := -1
+	bestCount := 0
+	bestMove := moves[0]
+	for _, move := range moves {
+		gain := MaterialGain(move)
+		if gain > bestGain {
+			bestGain = gain
+			bestMove = move
+			bestCount = 1
+		} else if gain == bestGain {
+			// reservoir sampling -> each of the equally good moves is picked with the same probability
+			bestCount++
+			if rng.Intn(bestCount) == 0 {
+				bestMove = move
+			}
+		}
+	}
+	return bestMove
+}
+
+// MaterialGain returns the material (in centipawns) won by a move i.e. the value of the
+// captured piece plus the value gained by a promotion
+func MaterialGain(move int) int {
+	gain := board.PieceValue[board.Captured(move)]
+	if move&board.MoveFlagEnPass != 0 {
+		gain = board.PieceValue[board.WhitePawn]
+	}
+	if promoted := board.Promoted(move); promoted != board.Empty {
+		gain += board.PieceValue[promoted] - board.PieceValue[board.WhitePawn]
+	}
+	return gain
+}
+
+// GivesCheck returns true if the move puts the opponent in check
+func GivesCheck(state *board.ChessBoard, move int) bool {
+	state.MakeMove(move)
+	inCheck := state.InCheck()
+	state.TakeMove()
+	return inCheck
+}
+
+// weightedMove picks a random move with a probability proportional to its weight
+func weightedMove(moves []int, rng *rand.Rand, weight func(move int) float64) int {
+	var weights [board.MaxPositionMoves]float64
+	total := 0.0
+	for i, move := range moves {
+		weights[i] = weight(move)
+		total += weights[i]
+	}
+
+	r := rng.Float64() * total
+	for i, w := range weights[:len(moves)] {
+		r -= w
+		if r < 0 {
+			return moves[i]
+		}
+	}
+	return moves[len(moves)-1]
+}
@@ -0,0 +1,88 @@
+package uct
+
+import (
+	"math"
+	"math/rand"
+	"slinky/board"
+	"testing"
+)
+
+// selectionFrequency returns how often the policy picks a move matching pred in the position
+func selectionFrequency(policy RolloutPolicy, pos *board.ChessBoard, pred func(move int) bool) float64 {
+	const samples = 4000
+	rng := rand.New(rand.NewSource(1))
+	moves := pos.GetMoves()
+	count := 0
+	for i := 0; i < samples; i++ {
+		if pred(policy.SelectMove(pos, moves, rng)) {
+			count++
+		}
+	}
+	return float64(count) / samples
+}
+
+// TestCapturePolicy checks that captures are picked in proportion to their MVV-LVA weight
+// and that the greedy policy always takes the most material
+func TestCapturePolicy(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	// exd5 wins the queen, the other 4 moves are quiet (the queen covers d1 and d2)
+	pos.ParseFen("4k3/8/8/3q4/4P3/8/8/4K3 w - - 0 1")
+	capture := pos.ParseMove("e4d5")
+	isCapture := func(move int) bool { return move == capture }
+
+	// the capture weighs 1 + 30 against 4 quiet moves of weight 1
+	expected := 31.0 / 35
+	if freq := selectionFrequency(CapturePolicy{}, &pos, isCapture); math.Abs(freq-expected) > 0.03 {
+		t.Errorf("expected the capture with a frequency of %.2f, got %.2f", expected, freq)
+	}
+	if freq := selectionFrequency(GreedyPolicy{}, &pos, isCapture); freq != 1 {
+		t.Errorf("expected the greedy policy to always capture the queen, got %.2f", freq)
+	}
+	if freq := selectionFrequency(UniformPolicy{}, &pos, isCapture); math.Abs(freq-1.0/5) > 0.03 {
+		t.Errorf("expected the uniform policy to pick the capture with a frequency of %.2f, got %.2f", 1.0/5, freq)
+	}
+}
+
+// TestTacticalPolicy checks the weighting of queen promotions and checking moves
+func TestTacticalPolicy(t *testing.T) {
+	board.AllInit()
+	policy := TacticalPolicy{CheckWeight: 8, PromotionWeight: 16}
+
+	pos := board.CreateBoard()
+	// 3 king moves and 4 promotions, none of them gives check
+	pos.ParseFen("8/1P6/8/8/8/8/8/K6k w - - 0 1")
+	queen := pos.ParseMove("b7b8q")
+	expected := 17.0 / 23
+	if freq := selectionFrequency(policy, &pos, func(move int) bool { return move == queen }); math.Abs(freq-expected) > 0.03 {
+		t.Errorf("expected the queen promotion with a frequency of %.2f, got %.2f", expected, freq)
+	}
+
+	// 10 rook moves and 5 king moves, only Ra8 gives check
+	pos.ParseFen("4k3/8/8/8/8/8/8/R3K3 w - - 0 1")
+	check := func(move int) bool { return GivesCheck(&pos, move) }
+	expected = 9.0 / 23
+	if freq := selectionFrequency(policy, &pos, check); math.Abs(freq-expected) > 0.03 {
+		t.Errorf("expected the checking move with a frequency of %.2f, got %.2f", expected, freq)
+	}
+}
+
+// TestNewRolloutPolicy checks that every name maps to its policy and unknown names to the uniform policy
+func TestNewRolloutPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RolloutPolicy
+	}{
+		{UniformPolicyName, UniformPolicy{}},
+		{CapturePolicyName, CapturePolicy{}},
+		{TacticalPolicyName, TacticalPolicy{CheckWeight: 8, PromotionWeight: 16}},
+		{GreedyPolicyName, GreedyPolicy{Epsilon: 0.25}},
+		{"unknown", UniformPolicy{}},
+		{"", UniformPolicy{}},
+	}
+	for _, test := range tests {
+		if policy := NewRolloutPolicy(test.name); policy != test.policy {
+			t.Errorf("%q: expected %#v, got %#v", test.name, test.policy, policy)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package uct
+
+import (
+	"math"
+	"slinky/board"
+)
+
+// Prior computes the prior probability of the moves of a position. The priors
+// guide the PUCT selection towards the moves that look promising before they
+// were searched
+type Prior interface {
+	// Priors returns the prior probability of each move (the probabilities add up to 1)
+	Priors(state *board.ChessBoard, moves []int, history *History) []float64
+}
+
+// Names of the available priors
+const (
+	UniformPriorName  = "uniform"
+	TacticalPriorName = "tactical"
+	HistoryPriorName  = "history"
+)
+
+// PriorNames lists the names of all priors
+var PriorNames = []string{UniformPriorName, TacticalPriorName, HistoryPriorName}
+
+// NewPrior returns the prior with the given name. Unknown names return the uniform prior
+func NewPrior(name string) Prior {
+	switch name {
+	case TacticalPriorName:
+		return TacticalPrior{CheckWeight: 4, PromotionWeight: 8}
+	case HistoryPriorName:
+		return HistoryPrior{Scale: 4}
+	default:
+		return UniformPrior{}
+	}
+}
+
+// UniformPrior gives every move the same probability
+type UniformPrior struct{}
+
+// Priors returns 1/len(moves) for every move
+func (p UniformPrior) Priors(state *board.ChessBoard, moves []int, history *History) []float64 {
+	return normalize(moves, func(move int) float64 { return 1 })
+}
+
+// TacticalPrior boosts captures (by MVV-LVA), checks and queen promotions
+type TacticalPrior struct {
+	CheckWeight     float64 // weight added to a checking move (quiet moves have a weight of 1)
+	PromotionWeight float64 // weight added to a queen promotion
+}
+
+// Priors returns the normalized weights of the moves
+func (p TacticalPrior) Priors(state *board.ChessBoard, moves []int, history *History) []float64 {
+	return normalize(moves, func(move int) float64 {
+		weight := 1 + float64(MvvLva(state, move))/6
+		if promoted := board.Promoted(move); promoted == board.WhiteQueen || promoted == board.BlackQueen {
+			weight += p.PromotionWeight
+		}
+		if GivesCheck(state, move) {
+			weight += p.CheckWeight
+		}
+		return weight
+	})
+}
+
+// HistoryPrior prefers the moves that did well elsewhere in the tree (history heuristic)
+type HistoryPrior struct {
+	Scale float64 // the weight of a move is exp(Scale * history score)
+}
+
+// Priors returns the softmax of the history scores of the moves
+func (p HistoryPrior) Priors(state *board.ChessBoard, moves []int, history *History) []float64 {
+	side := state.Side
+	return normalize(moves, func(move int) float64 {
+		return math.Exp(p.Scale * history.Score(side, move))
+	})
+}
+
+// normalize returns the weights of the moves scaled so that they add up to 1
+func normalize(moves []int, weight func(move int) float64) []float64 {
+	priors := make([]float64, len(moves))
+	total := 0.0
+	for i, move := range moves {
+		priors[i] = weight(move)
+		total += priors[i]
+	}
+	for i := range priors {
+		priors[i] /= total
+	}
+	return priors
+}
+
+// History collects the results of the moves played in the tree of a search,
+// regardless of the position in which they were played (history heuristic)
+type History struct {
+	wins   [2][board.InnerSquareNum][board.InnerSquareNum]float64 // indexed by side, from and to square
+	visits [2][board.InnerSquareNum][board.InnerSquareNum]float64
+}
+
+// Add adds the result of a playout (from the point of view of side) to the move
+func (h *History) Add(side, move int, result float64) {
+	from, to := board.Sq120ToSq64[board.FromSq(move)], board.Sq120ToSq64[board.ToSq(move)]
+	h.wins[side][from][to] += result
+	h.visits[side][from][to]++
+}
+
+// Score returns the mean result of the move for side, 0.5 for unknown moves
+func (h *History) Score(side, move int) float64 {
+	from, to := board.Sq120ToSq64[board.FromSq(move)], board.Sq120ToSq64[board.ToSq(move)]
+	return (h.wins[side][from][to] + 1) / (h.visits[side][from][to] + 2)
+}
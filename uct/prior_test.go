@@ -0,0 +1,54 @@
+package uct
+
+import (
+	"context"
+	"math"
+	"slinky/board"
+	"testing"
+	"time"
+)
+
+// TestPriorsAreProbabilities checks that every prior returns probabilities that add up to 1
+func TestPriorsAreProbabilities(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4")
+	moves := pos.GetMoves()
+
+	var history History
+	history.Add(board.White, moves[0], 1)
+
+	for _, name := range PriorNames {
+		priors := NewPrior(name).Priors(&pos, moves, &history)
+		total := 0.0
+		for _, p := range priors {
+			if p <= 0 {
+				t.Errorf("%s: expected positive priors, got %v", name, priors)
+				break
+			}
+			total += p
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("%s: expected priors to add up to 1, got %f", name, total)
+		}
+	}
+}
+
+// TestPUCTFindsMate checks that the PUCT selection finds the mate (Qxf7#) boosted by the tactical prior
+func TestPUCTFindsMate(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4")
+	info := board.SearchInfo{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	searcher := NewSearcher()
+	searcher.Config.Selection = PUCTSelectionName
+	searcher.Config.Prior = NewPrior(TacticalPriorName)
+	result := searcher.Search(ctx, &pos, &info)
+
+	if board.PrintMove(result.Move) != "h5f7" {
+		t.Errorf("expected move h5f7, got %s", board.PrintMove(result.Move))
+	}
+}
@@ -0,0 +1,81 @@
+package uct
+
+import (
+	"math"
+	"slinky/board"
+)
+
+// amafKey identifies a move by its from and to square in the all-moves-as-first statistics
+func amafKey(move int) int {
+	return board.Sq120ToSq64[board.FromSq(move)]*board.InnerSquareNum + board.Sq120ToSq64[board.ToSq(move)]
+}
+
+// amafTracker collects the moves played during a playout and updates the
+// all-moves-as-first (AMAF) statistics used by RAVE when the result is backpropagated.
+// A child of a node gets the result of the playout if its move was played by the
+// side to move at the node at any point after the node (in the tree or in the rollout)
+type amafTracker struct {
+	played   []int                                               // moves of the playout starting from the root
+	seen     [2][board.InnerSquareNum * board.InnerSquareNum]int // playout stamp of the moves seen, indexed by side and amafKey
+	stamp    int                                                 // incremented for every backpropagation
+	next     int                                                 // the moves played[next:] are marked as seen
+	rootSide int                                                 // side to move at the root
+}
+
+// start begins a new playout from the root
+func (a *amafTracker) start(rootSide int) {
+	a.played = a.played[:0]
+	a.rootSide = rootSide
+}
+
+// play records a move of the playout
+func (a *amafTracker) play(move int) {
+	a.played = append(a.played, move)
+}
+
+// backpropagate prepares the tracker for the update of the nodes of the playout
+func (a *amafTracker) backpropagate() {
+	a.stamp++
+	a.next = len(a.played)
+}
+
+// update adds the result of the playout to the AMAF statistics of the children of a node.
+// depth is the number of moves from the root to the node. The nodes must be updated from
+// the deepest to the root. score is the result from the point of view of resultPlayer
+func (a *amafTracker) update(nodes *arena, node *Node, depth, resultPlayer int, score float64) {
+	for a.next > depth {
+		a.next--
+		side := a.rootSide ^ (a.next & 1)
+		a.seen[side][amafKey(a.played[a.next])] = a.stamp
+	}
+
+	side := a.rootSide ^ (depth & 1) // side to move at the node
+	childScore := score
+	if side != resultPlayer {
+		childScore = 1 - score
+	}
+	for child := nodes.child(node); child != nil; child = nodes.sibling(child) {
+		if a.seen[side][amafKey(child.move)] == a.stamp {
+			child.amafWins += childScore
+			child.amafVisits++
+		}
+	}
+}
+
+// value returns the estimated value of the node from the point of view of playerJustMoved.
+// With RAVE the value is blended with the AMAF value using the schedule
+// beta = sqrt(k / (3 * visits + k)) where k is the equivalence parameter: the AMAF value
+// dominates while the node has few visits and fades out once it has many more than k.
+// With implicit minimax backups the minimax value gets a fixed weight
+func (n *Node) value(cfg *Config) float64 {
+	wins, visits := n.stats()
+	value := wins / visits
+	if cfg.RAVE && n.amafVisits > 0 {
+		beta := math.Sqrt(cfg.RAVEEquivalence / (3*visits + cfg.RAVEEquivalence))
+		value = (1-beta)*value + beta*n.amafWins/n.amafVisits
+	}
+	if minimax, ok := n.minimaxValue(cfg); cfg.ImplicitMinimax && ok {
+		value = (1-cfg.MinimaxWeight)*value + cfg.MinimaxWeight*minimax
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package uct
+
+import (
+	"slinky/board"
+	"testing"
+)
+
+// TestAmafUpdate checks that a child gets the AMAF result only if its move was played
+// later in the playout by the side to move at the node
+func TestAmafUpdate(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+
+	a := newArena(64)
+	root := createRootNode(a, &pos)
+	moves := map[string]*Node{}
+	for _, move := range append([]int{}, root.untriedMoves...) {
+		pos.MakeMove(move)
+		moves[board.PrintMove(move)] = root.addChild(a, move, &pos)
+		pos.TakeMove()
+	}
+
+	// playout e2e4 e7e5 d2d4 (rollout) won by white
+	var amaf amafTracker
+	amaf.start(board.White)
+	for _, move := range []string{"e2e4", "e7e5", "d2d4"} {
+		m := pos.ParseMove(move)
+		pos.MakeMove(m)
+		amaf.play(m)
+	}
+	amaf.backpropagate()
+	amaf.update(a, root, 0, board.White, 1)
+
+	for move, child := range moves {
+		expected := 0.0
+		if move == "e2e4" || move == "d2d4" {
+			expected = 1
+		}
+		if child.amafVisits != expected || child.amafWins != expected {
+			t.Errorf("%s: expected AMAF %.0f/%.0f, got %.0f/%.0f", move, expected, expected, child.amafWins, child.amafVisits)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package uct
+
+import (
+	"math"
+	"slinky/board"
+)
+
+// Evaluator returns a static evaluation of the position in centipawns from the
+// point of view of the side to move
+type Evaluator func(state *board.ChessBoard) int
+
+// quiescenceDepth maximum number of captures played to reach a quiet position
+const quiescenceDepth = 8
+
+// rolloutBuffer holds the moves of the current rollout step, it is reused by every
+// step so that the rollouts do not allocate memory
+type rolloutBuffer struct {
+	moveList board.MoveList // pseudo-legal moves sampled by the uniform policy
+	moves    []int          // legal moves for the other policies
+}
+
+// rolloutMove returns the next move of a rollout or board.NoMove if the side to move
+// has no legal move. The uniform policy samples the pseudo-legal moves and tests their
+// legality lazily, the other policies choose from all legal moves
+func (t *tree) rolloutMove(state *board.ChessBoard) int {
+	if _, uniform := t.cfg.Policy.(UniformPolicy); uniform && t.cfg.RolloutMinimax == 0 {
+		t.rollout.moveList.Count = 0
+		state.GenerateAllMoves(&t.rollout.moveList)
+		return state.RandomLegalMove(&t.rollout.moveList, t.rng.Intn)
+	}
+
+	if t.rollout.moves == nil {
+		t.rollout.moves = make([]int, 0, board.MaxPositionMoves)
+	}
+	moves := state.AppendMoves(t.rollout.moves[:0])
+	if len(moves) == 0 {
+		return board.NoMove
+	}
+	if t.cfg.RolloutMinimax > 0 {
+		m, found, candidates := rolloutMinimax(state, moves, t.cfg.RolloutMinimax)
+		if found {
+			return m
+		}
+		moves = candidates
+	}
+	return t.cfg.Policy.SelectMove(state, moves, t.rng)
+}
+
+// WinProbability maps a centipawn score to the expected score (0-1) of the side
+// to move using a logistic function. A score of scale centipawns maps to ~91%
+func WinProbability(cp int, scale float64) float64 {
+	return 1 / (1 + math.Pow(10, -float64(cp)/scale))
+}
+
+// maxCentipawns bound of the centipawn scores converted from an expected score
+const maxCentipawns = 2000
+
+// Centipawns converts an expected score (0-1) of the side to move to centipawns. It is
+// the inverse of WinProbability, so the scores are calibrated with the same scale as the
+// evaluation used by the rollouts. The conversion is monotonic, positive scores are good
+// for the side to move and the result is limited to +-maxCentipawns
+func Centipawns(score, scale float64) int {
+	if score <= 0 {
+		return -maxCentipawns
+	} else if score >= 1 {
+		return maxCentipawns
+	}
+	cp := -scale * math.Log10(1/score-1)
+	return int(math.Round(math.Max(-maxCentipawns, math.Min(maxCentipawns, cp))))
+}
+
+// evaluate returns the expected score of the side to move for a position in
+// which the rollout was cut off. Pending captures are resolved first, so that
+// the position is not evaluated in the middle of an exchange
+func (t *tree) evaluate(state *board.ChessBoard) float64 {
+	cp := t.quiesce(state, -board.PieceValue[board.WhiteKing], board.PieceValue[board.WhiteKing], quiescenceDepth)
+	return WinProbability(cp, t.cfg.EvalScale)
+}
+
+// quiesce is an alpha-beta search over captures only. It returns the evaluation
+// of the position in centipawns from the point of view of the side to move
+func (t *tree) quiesce(state *board.ChessBoard, alpha, beta, depth int) int {
+	standPat := t.cfg.Evaluate(state)
+	if depth == 0 || standPat >= beta {
+		return standPat
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	var moveList board.MoveList
+	state.GenerateAllMoves(&moveList)
+
+	for i := 0; i < moveList.Count; i++ {
+		move := moveList.Moves[i]
+		if MaterialGain(move) == 0 || !state.IsMoveLegal(move) {
+			continue
+		}
+
+		state.MakeMove(move)
+		score := -t.quiesce(state, -beta, -alpha, depth-1)
+		state.TakeMove()
+
+		if score >= beta {
+			return score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
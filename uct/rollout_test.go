@@ -0,0 +1,139 @@
+package uct
+
+import (
+	"context"
+	"math/rand"
+	"slinky/board"
+	"testing"
+)
+
+// TestRolloutStepAllocations checks that a rollout step (choosing and playing a move
+// and testing for a draw) does not allocate with any of the rollout policies
+func TestRolloutStepAllocations(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	for _, name := range PolicyNames {
+		for _, minimax := range []int{0, 2} {
+			cfg := DefaultConfig()
+			cfg.Policy = NewRolloutPolicy(name)
+			cfg.RolloutMinimax = minimax
+			tr := &tree{cfg: cfg, rng: rand.New(rand.NewSource(1))}
+
+			allocs := testing.AllocsPerRun(100, func() {
+				move := tr.rolloutMove(&pos)
+				pos.MakeMove(move)
+				pos.IsDrawn()
+				pos.TakeMove()
+			})
+			if allocs != 0 {
+				t.Errorf("%s policy with rollout minimax %d: expected no allocations per step, got %.1f", name, minimax, allocs)
+			}
+		}
+	}
+}
+
+// TestRolloutMoveTerminal checks that no move is returned if the side to move is mated
+func TestRolloutMoveTerminal(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+
+	tr := &tree{cfg: DefaultConfig(), rng: rand.New(rand.NewSource(1))}
+	if move := tr.rolloutMove(&pos); move != board.NoMove {
+		t.Errorf("expected no move, got %s", board.PrintMove(move))
+	}
+}
+
+// TestQuiesce checks that a hanging piece is taken before the position is evaluated
+// and that a losing capture is not played
+func TestQuiesce(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	tr := &tree{cfg: DefaultConfig(), rng: rand.New(rand.NewSource(1))}
+	maxScore := board.PieceValue[board.WhiteKing]
+
+	// exd5 wins the queen: white is lost by the static evaluation but ahead after the capture
+	pos.ParseFen("4k3/8/8/3q4/4P3/8/8/4K3 w - - 0 1")
+	key := pos.PosKey()
+	if standPat := tr.cfg.Evaluate(&pos); standPat >= 0 {
+		t.Fatalf("expected black to be ahead by the static evaluation, got %d", standPat)
+	}
+	if score := tr.quiesce(&pos, -maxScore, maxScore, quiescenceDepth); score <= 0 {
+		t.Errorf("expected white to be ahead after taking the queen, got %d", score)
+	}
+	if score := tr.evaluate(&pos); score <= 0.5 {
+		t.Errorf("expected an expected score above 0.5 for white, got %.2f", score)
+	}
+	if pos.PosKey() != key {
+		t.Errorf("the position was not restored")
+	}
+
+	// Qxd6 cxd6 loses the queen for a pawn -> white stands pat
+	pos.ParseFen("4k3/2p5/3p4/8/8/8/8/3QK3 w - - 0 1")
+	standPat := tr.cfg.Evaluate(&pos)
+	if score := tr.quiesce(&pos, -maxScore, maxScore, quiescenceDepth); score != standPat {
+		t.Errorf("expected the static evaluation %d, got %d", standPat, score)
+	}
+}
+
+// TestRolloutCutoffScore checks that the evaluation of a cut off rollout is backed up
+// in favour of the side that is ahead, whichever side moved last in the rollout
+func TestRolloutCutoffScore(t *testing.T) {
+	board.AllInit()
+	tests := []struct {
+		fen   string
+		ahead bool // the side to move at the root is ahead
+	}{
+		{"4k3/8/8/8/8/8/8/3QK3 w - - 0 1", true},
+		{"3qk3/8/8/8/8/8/8/4K3 w - - 0 1", false},
+		{"3qk3/8/8/8/8/8/8/4K3 b - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/3QK3 b - - 0 1", false},
+	}
+
+	for _, test := range tests {
+		for _, depth := range []int{1, 2} {
+			pos := board.CreateBoard()
+			pos.ParseFen(test.fen)
+			s := NewSearcher()
+			s.Config.Threads = 1
+			s.Config.RolloutDepth = depth
+			s.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 300, Seed: 1})
+
+			wins, visits := 0.0, 0.0
+			for _, rootMove := range s.RootMoves() {
+				wins += rootMove.Wins
+				visits += rootMove.Visits
+			}
+			score := wins / visits
+			if (test.ahead && score < 0.8) || (!test.ahead && score > 0.2) {
+				t.Errorf("%s with rollout depth %d: unexpected score %.2f for the side to move", test.fen, depth, score)
+			}
+		}
+	}
+}
+
+// BenchmarkRollout plays complete random games from a middlegame position
+func BenchmarkRollout(b *testing.B) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	tr := &tree{cfg: DefaultConfig(), rng: rand.New(rand.NewSource(1))}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plies := 0
+		for move := tr.rolloutMove(&pos); move != board.NoMove; move = tr.rolloutMove(&pos) {
+			pos.MakeMove(move)
+			plies++
+			if pos.IsDrawn() {
+				break
+			}
+		}
+		for ; plies > 0; plies-- {
+			pos.TakeMove()
+		}
+	}
+}
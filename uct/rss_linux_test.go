@@ -0,0 +1,16 @@
+//go:build linux
+
+package uct
+
+import (
+	"syscall"
+	"testing"
+)
+
+// reportPeakRSS adds the peak resident set size of the process to the benchmark results
+func reportPeakRSS(b *testing.B) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err == nil {
+		b.ReportMetric(float64(usage.Maxrss), "peak-rss-KB")
+	}
+}
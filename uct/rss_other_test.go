@@ -0,0 +1,8 @@
+//go:build !linux
+
+package uct
+
+import "testing"
+
+// reportPeakRSS is not supported on this platform
+func reportPeakRSS(b *testing.B) {}
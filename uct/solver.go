@@ -0,0 +1,84 @@
+package uct
+
+import "slinky/board"
+
+// Proof status of a node (MCTS-Solver). The status is from the point of view of
+// the player that made the move leading to the node (playerJustMoved)
+const (
+	Unproven int = iota
+	ProvenWin
+	ProvenLoss
+	ProvenDraw
+)
+
+// setTerminal marks a node whose position is game over with the result of the game
+// from the point of view of node.playerJustMoved
+func (n *Node) setTerminal(result board.Result) {
+	switch result {
+	case board.Win:
+		n.proof = ProvenWin
+	case board.Loss:
+		n.proof = ProvenLoss
+	default:
+		n.proof = ProvenDraw
+	}
+	n.proofPlies = 0
+}
+
+// updateProof proves a node from the proof status of its children:
+//   - if any child is a proven win for the side to move, the node is a proven loss
+//   - if all moves were tried and all children are proven, the node is a proven draw
+//     if any child is a draw, otherwise it is a proven win (all replies lose)
+func (n *Node) updateProof(a *arena) {
+	if n.proof != Unproven || n.children == 0 {
+		return
+	}
+
+	allProven := len(n.untriedMoves) == 0
+	draw := false
+	longestLoss := 0
+	for child := a.child(n); child != nil; child = a.sibling(child) {
+		switch child.proof {
+		case ProvenWin:
+			// the side to move has a winning move -> pick the quickest win
+			if n.proof != ProvenLoss || child.proofPlies+1 < n.proofPlies {
+				n.proof = ProvenLoss
+				n.proofPlies = child.proofPlies + 1
+			}
+		case ProvenLoss:
+			// the side to move will delay the loss as long as possible
+			if child.proofPlies+1 > longestLoss {
+				longestLoss = child.proofPlies + 1
+			}
+		case ProvenDraw:
+			draw = true
+		default:
+			allProven = false
+		}
+	}
+
+	if n.proof != Unproven || !allProven {
+		return
+	}
+	if draw {
+		n.proof = ProvenDraw
+		n.proofPlies = 0
+	} else {
+		n.proof = ProvenWin
+		n.proofPlies = longestLoss
+	}
+}
+
+// MateScore converts the proof of a root move to the number of moves until mate as
+// used by the UCI protocol (positive if the side to move mates, negative if it gets
+// mated). 0 is returned if the move does not lead to a proven mate
+func MateScore(proof, proofPlies int) int {
+	plies := proofPlies + 1 // the root move itself
+	switch proof {
+	case ProvenWin:
+		return (plies + 1) / 2
+	case ProvenLoss:
+		return -plies / 2
+	}
+	return 0
+}
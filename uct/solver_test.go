@@ -0,0 +1,40 @@
+package uct
+
+import (
+	"context"
+	"slinky/board"
+	"testing"
+	"time"
+)
+
+// TestSolverFindsMate checks that forced mates are proven and reported as mate scores
+func TestSolverFindsMate(t *testing.T) {
+	board.AllInit()
+
+	tests := []struct {
+		fen  string
+		move string
+		mate int
+	}{
+		{"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", "d1d8", 1},
+		{"7k/8/5K2/8/8/8/8/6R1 w - - 0 1", "f6f7", 2},
+	}
+
+	for _, test := range tests {
+		pos := board.CreateBoard()
+		pos.ParseFen(test.fen)
+		info := board.SearchInfo{}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		searcher := NewSearcher()
+		result := searcher.Search(ctx, &pos, &info)
+		cancel()
+
+		if board.PrintMove(result.Move) != test.move {
+			t.Errorf("%s: expected move %s, got %s", test.fen, test.move, board.PrintMove(result.Move))
+		}
+		if lines := searcher.Progress(1).Lines; len(lines) == 0 || lines[0].Mate != test.mate {
+			t.Errorf("%s: expected mate %d, got %+v", test.fen, test.mate, lines)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package uct
+
+import (
+	"slinky/board"
+	"time"
+)
+
+// playoutStats are the counters of the playouts of a tree during a search
+type playoutStats struct {
+	start        time.Time // start of the search
+	end          time.Time // end of the search (zero while it is running)
+	rolloutPlies int
+	mates        int // rollouts (or tree nodes) that ended in a checkmate
+	draws        int
+	cutoffs      int // rollouts that were cut off and evaluated (see Config.RolloutDepth)
+
+	selection, expansion, rollout, backup time.Duration
+}
+
+// countResult records how the rollout of a playout ended
+func (ps *playoutStats) countResult(result board.Result, plies int) {
+	ps.rolloutPlies += plies
+	switch result {
+	case board.NoWinner:
+		ps.cutoffs++
+	case board.Draw:
+		ps.draws++
+	default:
+		ps.mates++
+	}
+}
+
+// SearchStats summarizes the last (or running) search of all trees
+type SearchStats struct {
+	Nodes        int     // nodes in all trees
+	MaxDepth     int     // deepest tree depth reached by a playout
+	AverageDepth float64 // mean tree depth reached by the playouts
+	Branching    float64 // mean number of children of the expanded nodes
+	Workers      int
+	Playouts     int
+	RolloutPlies float64       // mean length of the rollouts in plies
+	Rate         float64       // playouts per second of all workers together
+	WorkerRate   float64       // playouts per second of a single worker
+	Elapsed      time.Duration // duration of the search
+
+	// share of the playouts that ended in a checkmate, in a draw or were cut off and evaluated
+	MateShare, DrawShare, CutoffShare float64
+
+	// time spent in the phases of the playouts by all workers together
+	Selection, Expansion, Rollout, Backup time.Duration
+}
+
+// Stats returns the statistics of the last search. It is safe to call while a
+// search is running. Counting the expanded nodes walks all trees, so it takes a
+// while for big trees
+func (s *Searcher) Stats() SearchStats {
+	var stats SearchStats
+	var results playoutStats
+	depthSum, expanded, roots := 0, 0, 0
+	for _, t := range s.trees {
+		t.mu.Lock()
+		stats.Workers++
+		stats.Playouts += t.simulations
+		depthSum += t.depthSum
+		if t.selDepth > stats.MaxDepth {
+			stats.MaxDepth = t.selDepth
+		}
+		if t.root != nil {
+			roots++
+			stats.Nodes += t.arena.live
+			t.root.forEach(t.arena, func(n *Node) {
+				if n.children > 0 {
+					expanded++
+				}
+			})
+		}
+
+		elapsed := t.stats.end.Sub(t.stats.start)
+		if t.stats.end.IsZero() {
+			elapsed = time.Since(t.stats.start)
+		}
+		if elapsed > stats.Elapsed && !t.stats.start.IsZero() {
+			stats.Elapsed = elapsed
+		}
+
+		results.rolloutPlies += t.stats.rolloutPlies
+		results.mates += t.stats.mates
+		results.draws += t.stats.draws
+		results.cutoffs += t.stats.cutoffs
+		stats.Selection += t.stats.selection
+		stats.Expansion += t.stats.expansion
+		stats.Rollout += t.stats.rollout
+		stats.Backup += t.stats.backup
+		t.mu.Unlock()
+	}
+
+	if expanded > 0 {
+		// every node except the roots is the child of an expanded node
+		stats.Branching = float64(stats.Nodes-roots) / float64(expanded)
+	}
+	if stats.Playouts == 0 {
+		return stats
+	}
+	playouts := float64(stats.Playouts)
+	stats.AverageDepth = float64(depthSum) / playouts
+	stats.RolloutPlies = float64(results.rolloutPlies) / playouts
+	stats.MateShare = float64(results.mates) / playouts
+	stats.DrawShare = float64(results.draws) / playouts
+	stats.CutoffShare = float64(results.cutoffs) / playouts
+	if stats.Elapsed > 0 {
+		stats.Rate = playouts / stats.Elapsed.Seconds()
+		stats.WorkerRate = stats.Rate / float64(stats.Workers)
+	}
+	return stats
+}
@@ -0,0 +1,44 @@
+package uct
+
+import (
+	"context"
+	"math"
+	"slinky/board"
+	"testing"
+)
+
+// TestSearchStats checks that the statistics add up after a node-limited search
+func TestSearchStats(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("r1b1k2r/ppp2ppp/2n5/3q4/1b1P4/2N2N2/PP3PPP/R2QKB1R w KQkq - 0 9")
+
+	s := NewSearcher()
+	s.Config.Threads = 2
+	s.Config.RolloutDepth = 4
+	s.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 200, Seed: 1})
+
+	stats := s.Stats()
+	if stats.Workers != 2 || stats.Playouts != 200 {
+		t.Errorf("expected 200 playouts of 2 workers, got %d of %d", stats.Playouts, stats.Workers)
+	}
+	// every playout expands a node
+	if stats.Nodes != 202 || stats.Branching <= 1 {
+		t.Errorf("expected 202 nodes and a branching factor above 1, got %d nodes and %.2f", stats.Nodes, stats.Branching)
+	}
+	if stats.MaxDepth < 1 || stats.AverageDepth < 1 || stats.AverageDepth > float64(stats.MaxDepth) {
+		t.Errorf("unexpected depths: max %d mean %.2f", stats.MaxDepth, stats.AverageDepth)
+	}
+	if stats.RolloutPlies <= 0 || stats.RolloutPlies > 4 {
+		t.Errorf("expected rollouts of at most 4 plies, got %.2f", stats.RolloutPlies)
+	}
+	if shares := stats.MateShare + stats.DrawShare + stats.CutoffShare; math.Abs(shares-1) > 1e-9 || stats.CutoffShare == 0 {
+		t.Errorf("expected the rollout results to add up to 1 with cutoffs, got %.3f (cutoff %.3f)", shares, stats.CutoffShare)
+	}
+	if stats.Rollout <= 0 || stats.Elapsed <= 0 || stats.Rate <= 0 {
+		t.Errorf("expected the search to be timed, got rollout %v elapsed %v rate %.0f", stats.Rollout, stats.Elapsed, stats.Rate)
+	}
+	if rate := float64(stats.Playouts) / stats.Elapsed.Seconds(); math.Abs(stats.Rate-rate) > 1e-6*rate || stats.WorkerRate != stats.Rate/2 {
+		t.Errorf("expected a rate of %.0f/s (%.0f/s per worker), got %.0f/s (%.0f/s)", rate, rate/2, stats.Rate, stats.WorkerRate)
+	}
+}
@@ -0,0 +1,101 @@
+package uct
+
+import "unsafe"
+
+// ttBucketSize number of entries in each bucket of the transposition table
+const ttBucketSize = 4
+
+// ttEntry holds the statistics shared by all nodes of the same position
+type ttEntry struct {
+	key    uint64  // position key (board.ChessBoard.PosKey)
+	wins   float64 // wins from the point of view of the player that just moved
+	visits float64
+}
+
+// transpositionTable shares the statistics of a position between all nodes of a tree
+// that reach the position through different move orders. The tree itself stays a
+// tree (each node has a single parent), the table turns the statistics into a DAG:
+// the value of a node is the value of its position, while the exploration term of
+// UCT still uses the visits of the edge (the node)
+type transpositionTable struct {
+	entries []ttEntry
+	mask    uint64 // number of buckets - 1 (the number of buckets is a power of 2)
+	used    int    // number of entries in use
+	size    int    // size of the table in bytes as requested on creation
+}
+
+// newTranspositionTable creates a table that uses at most size bytes
+// nil is returned if the size is too small for a single bucket
+func newTranspositionTable(size int) *transpositionTable {
+	bucketBytes := ttBucketSize * int(unsafe.Sizeof(ttEntry{}))
+	if size < bucketBytes {
+		return nil
+	}
+
+	buckets := 1
+	for buckets*2*bucketBytes <= size {
+		buckets *= 2
+	}
+	return &transpositionTable{
+		entries: make([]ttEntry, buckets*ttBucketSize),
+		mask:    uint64(buckets - 1),
+		size:    size,
+	}
+}
+
+// entry returns the entry of the position and if it was found. If the position is
+// not stored yet, the least visited entry of its bucket is replaced
+func (tt *transpositionTable) entry(key uint64) (e *ttEntry, found bool) {
+	first := (key & tt.mask) * ttBucketSize
+	bucket := tt.entries[first : first+ttBucketSize]
+
+	var replace *ttEntry
+	for i := range bucket {
+		e = &bucket[i]
+		if e.key == key && e.visits > 0 {
+			return e, true
+		}
+		if replace == nil || e.visits < replace.visits {
+			replace = e
+		}
+	}
+
+	if replace.visits == 0 {
+		tt.used++
+	}
+	*replace = ttEntry{key: key}
+	return replace, false
+}
+
+// update adds the result of a playout to the entry of the node's position
+func (tt *transpositionTable) update(n *Node, result float64) {
+	if !n.hasEntry() {
+		entry, found := tt.entry(n.posKey)
+		if !found {
+			// seed a new entry with what the node has seen before this playout
+			entry.wins, entry.visits = n.wins-result, n.visits-1
+		}
+		n.entry = entry
+	}
+	n.entry.wins += result
+	n.entry.visits++
+}
+
+// hashFull returns the usage of the table in permille
+func (tt *transpositionTable) hashFull() int {
+	return tt.used * 1000 / len(tt.entries)
+}
+
+// hasEntry returns true if the node has an entry in the transposition table which was not replaced
+func (n *Node) hasEntry() bool {
+	return n.entry != nil && n.entry.key == n.posKey
+}
+
+// stats returns the wins and visits used to evaluate the node. With a transposition
+// table the statistics of all nodes with the same position are used
+func (n *Node) stats() (wins, visits float64) {
+	if n.hasEntry() && n.entry.visits >= n.visits {
+		return n.entry.wins, n.entry.visits
+	}
+	return n.wins, n.visits
+}
@@ -0,0 +1,35 @@
+package uct
+
+import "testing"
+
+// TestTranspositionTableSharesStatistics checks that nodes of the same position share
+// their statistics and that a full bucket replaces its least visited entry
+func TestTranspositionTableSharesStatistics(t *testing.T) {
+	tt := newTranspositionTable(1 << 16)
+
+	a := &Node{posKey: 42, wins: 1, visits: 1}
+	b := &Node{posKey: 42, wins: 0, visits: 1}
+	tt.update(a, 1)
+	tt.update(b, 0)
+
+	if wins, visits := a.stats(); wins != 1 || visits != 2 {
+		t.Errorf("expected shared statistics 1/2, got %.0f/%.0f", wins, visits)
+	}
+
+	// fill the bucket of the position with more visited positions
+	buckets := tt.mask + 1
+	for i := uint64(1); i <= ttBucketSize; i++ {
+		n := &Node{posKey: 42 + i*buckets, wins: 5, visits: 5}
+		tt.update(n, 1)
+	}
+
+	if a.hasEntry() {
+		t.Errorf("expected the least visited entry to be replaced")
+	}
+	if wins, visits := a.stats(); wins != 1 || visits != 1 {
+		t.Errorf("expected the node's own statistics 1/1 after replacement, got %.0f/%.0f", wins, visits)
+	}
+	if tt.used != ttBucketSize {
+		t.Errorf("expected %d used entries, got %d", ttBucketSize, tt.used)
+	}
+}
@@ -1,230 +1,557 @@
 package uct
 
 import (
-	"fmt"
+	"context"
+	"math"
 	"math/rand"
+	"runtime"
 	"slinky/board"
-	"sort"
+	"slinky/eval"
+	"sync"
 	"time"
 )
 
-type rankedMove struct {
-	move  int
-	score float64
+// SearchResult holds the outcome of a search
+type SearchResult struct {
+	Move        int     // best move found
+	PonderMove  int     // expected reply to the best move (board.NoMove if unknown)
+	Score       float64 // expected score of the best move from the point of view of the side to move
+	Simulations int     // total number of simulations done by all workers
 }
 
-type uctResult struct {
-	state            *board.ChessBoard
-	move             int
-	wins             float64
-	visits           float64
-	totalSimulations int
+// tree is a single search tree owned by one worker goroutine.
+// Root parallelization is used: every worker grows its own tree from the same
+// root position and the statistics of the root moves are merged after the search
+type tree struct {
+	mu          sync.Mutex // held by the worker during a playout, so the tree can be inspected during search
+	root        *Node
+	arena       *arena           // nodes of the tree
+	state       board.ChessBoard // private copy of the root position
+	simulations int
+	depthSum    int        // sum of the tree depths reached by all playouts (used for the mean depth)
+	selDepth    int        // deepest tree depth reached by a playout
+	cfg         Config     // copy of the searcher configuration used during the current search
+	rng         *rand.Rand // random numbers of the playouts, seeded at the start of every search
+	tt          *transpositionTable
+	history     History // results of the moves played in the tree (used by the history prior)
+	amaf        amafTracker
+	rollout     rolloutBuffer
+	stats       playoutStats
+	killers     Killers // moves proven to win at each tree depth (used by the move ordering)
+	restricted  bool    // the root moves are restricted to the searchmoves, the tree can not be reused
 }
 
-func uct(rootstate *board.ChessBoard, originMove int, timeData timeInfo) uctResult {
-	rootstate.MakeMove(originMove)
-	/* Check for immediate result
-	It is possible the game is already over by this point
-	in which the value of the move should be immediately computed and
-	put in the result from the view point of the enemy
-	since here moves are evaluated from that viewpoint */
-	isResult, result := isImmediateResult(rootstate, originMove)
-	if isResult == true {
-		return result
+// Searcher runs UCT searches and keeps the search trees between searches so
+// that the part of the tree which is still relevant can be reused
+// (i.e. after a ponderhit or when the opponent played the expected reply)
+type Searcher struct {
+	Config Config
+
+	trees []*tree
+}
+
+// Config holds the parameters of the search
+type Config struct {
+	Policy           RolloutPolicy // policy used to choose the moves of the rollouts
+	RolloutDepth     int           // maximum number of rollout moves before the position is evaluated (0 - no limit)
+	Evaluate         Evaluator     // static evaluation used when a rollout is cut off
+	EvalScale        float64       // centipawn score that maps to a ~91% winning chance (see WinProbability)
+	HashSize         int           // size of the transposition tables of all trees together in MB (0 - disabled)
+	TreeMemory       int           // memory of the nodes of all trees together in MB (see NodeBytes)
+	Selection        string        // selection strategy (see SelectionNames)
+	Prior            Prior         // prior of the moves used by the PUCT selection
+	Exploration      float64       // exploration constant of the selection strategy
+	FPUReduction     float64       // PUCT: value reduction of the untried moves relative to their parent
+	RAVE             bool          // blend the node values with all-moves-as-first statistics
+	RAVEEquivalence  float64       // RAVE: number of visits at which the AMAF and the node value have the same weight (see Node.value)
+	Widening         bool          // progressive widening: limit the number of children by the visits of a node
+	WideningFactor   float64       // the number of children allowed is ceil(WideningFactor * visits^WideningExponent)
+	WideningExponent float64
+	MoveSelection    string  // policy that chooses the move to play (see MoveSelectionNames)
+	RolloutMinimax   int     // depth (0-2) of the minimax search before every rollout move (0 - disabled)
+	ImplicitMinimax  bool    // keep minimax values of the static evaluation in the nodes
+	MinimaxWeight    float64 // weight of the minimax value in the selection (0-1)
+	Threads          int     // number of worker goroutines, each with its own tree (0 - one per CPU)
+	Contempt         int     // centipawns by which the engine values a draw below an even position (negative - draws are welcome)
+	ContemptPhase    bool    // scale the contempt with the game phase (see eval.Contempt)
+
+	drawShift [2]float64 // shift of the draw score from 0.5 for each side during a search (see setContempt)
+}
+
+// DefaultConfig returns the default search parameters
+func DefaultConfig() Config {
+	return Config{
+		Policy:           UniformPolicy{},
+		RolloutDepth:     0,
+		Evaluate:         eval.Evaluate,
+		EvalScale:        400,
+		HashSize:         0,
+		TreeMemory:       256,
+		Selection:        UCB1SelectionName,
+		Prior:            UniformPrior{},
+		Exploration:      math.Sqrt2,
+		FPUReduction:     0.2,
+		RAVE:             false,
+		RAVEEquivalence:  250,
+		Widening:         false,
+		WideningFactor:   2,
+		WideningExponent: 0.5,
+		MoveSelection:    RobustChildName,
+		RolloutMinimax:   0,
+		ImplicitMinimax:  false,
+		MinimaxWeight:    0.3,
+		Threads:          0,
+		Contempt:         0,
+		ContemptPhase:    false,
 	}
+}
 
-	rootnode := CreateRootNode(rootstate)
-	state := rootstate
+// NewSearcher creates a searcher with one tree per available CPU
+func NewSearcher() *Searcher {
+	s := &Searcher{Config: DefaultConfig()}
+	s.Clear()
+	return s
+}
 
-	simulations := 0
+// Clear discards the search trees (i.e. when a new game starts)
+func (s *Searcher) Clear() {
+	s.trees = nil
+	for i := 0; i < s.threads(); i++ {
+		s.trees = append(s.trees, &tree{})
+	}
+}
 
-	elapsedTime := time.Since(timeData.startTime).Seconds() * 1000 // get elapsed time in ms
-	for timeData.isTimeSet == true && elapsedTime < float64(timeData.stopTime) {
-		simulations++ // count number of simulations done
-		node := &rootnode
-		movesToRoot := 0
+// threads returns the number of worker goroutines of a search
+func (s *Searcher) threads() int {
+	if s.Config.Threads > 0 {
+		return s.Config.Threads
+	}
+	return runtime.NumCPU()
+}
 
-		// Select stage
-		// node is fully expanded and non-terminal
-		for len(node.untriedMoves) == 0 && len(node.childNodes) > 0 {
-			node = node.SelectChild()
-			state.MakeMove(node.move)
-			movesToRoot++
-		}
+// playout performs a single select, expand, rollout and backpropagate iteration
+func (t *tree) playout() {
+	start := time.Now()
+	node := t.root
+	state := &t.state
+	movesToRoot := 0
+	t.amaf.start(state.Side)
+
+	// Select stage
+	// descend while the node does not need to be expanded and is non-terminal
+	if t.arena.full() {
+		t.arena.collect(t.root)
+	}
 
-		// Expand
-		// if we can expand (i.e. state/node is non-terminal)
+	for {
 		if len(node.untriedMoves) > 0 {
-			move := node.untriedMoves[rand.Intn(len(node.untriedMoves))]
-			state.MakeMove(move)
-			movesToRoot++
-			// add child and descend tree
-			node = node.AddChild(move, state)
+			if t.cfg.Selection == PUCTSelectionName && node.priors == nil {
+				node.setPriors(state, t.cfg.Prior, &t.history)
+			} else if t.cfg.Widening && !node.ordered {
+				node.orderMoves(state, movesToRoot, &t.killers, &t.history)
+			}
+		}
+		child := node.selectChild(t.arena, &t.cfg)
+		if child == nil {
+			// expand the node, or all children are proven -> the node gets proven during backpropagation
+			break
 		}
+		node = child
+		state.MakeMove(node.move)
+		t.amaf.play(node.move)
+		movesToRoot++
+	}
 
-		// Rollout
-		//  - this can often be made orders of magnitude quicker
-		//    using a state.GetRandomMove() function
+	expansionStart := time.Now()
+	t.stats.selection += expansionStart.Sub(start)
 
-		// while state is non-terminal
-		for state.GetResult(state.GetPlayerJustMoved()) == board.NoWinner {
-			moves := state.GetMoves()
-			m := moves[rand.Intn(len(moves))]
-			state.MakeMove(m)
-			movesToRoot++
+	// Expand
+	// if we can expand (i.e. state/node is non-terminal and there is room for a node)
+	if len(node.untriedMoves) > 0 && !t.arena.full() {
+		move := node.untriedMoves[t.rng.Intn(len(node.untriedMoves))]
+		if t.cfg.Selection == PUCTSelectionName || t.cfg.Widening {
+			move = node.untriedMoves[len(node.untriedMoves)-1] // highest prior or best ordered move
 		}
+		state.MakeMove(move)
+		t.amaf.play(move)
+		movesToRoot++
+		// add child and descend tree
+		node = node.addChild(t.arena, move, state)
+	}
 
-		// Backpropagate
-		// backpropagate from the expanded node and work back to the root node
-		for node != nil {
-			// state is terminal.
-			// Update node with result from POV of node.playerJustMoved
-			gameResult := state.GetResult(node.playerJustMoved)
-			node.Update(float64(gameResult))
-			node = node.parent
+	rolloutStart := time.Now()
+	t.stats.expansion += rolloutStart.Sub(expansionStart)
+
+	treeDepth := movesToRoot
+	t.depthSum += movesToRoot
+	if movesToRoot > t.selDepth {
+		t.selDepth = movesToRoot
+	}
+
+	// Rollout
+	//  - this can often be made orders of magnitude quicker
+	//    using a state.GetRandomMove() function
+	rolloutMoves := 0
+	result := state.GetResult(state.GetPlayerJustMoved())
+	if result != board.NoWinner && node != t.root {
+		// the game is over in the position of the node -> its result is known for sure
+		node.setTerminal(result)
+	}
+	if t.cfg.ImplicitMinimax && !node.hasMinimax {
+		if result != board.NoWinner {
+			node.setMinimax(t.cfg.resultScore(result, state.GetPlayerJustMoved()))
+		} else {
+			node.setMinimax(1 - t.evaluate(state))
 		}
+	}
 
-		// Revert all the made moves
-		for j := 0; j < movesToRoot; j++ {
-			state.TakeMove()
+	// while state is non-terminal: the moves are generated once per step, which also
+	// tells if the side to move is checkmated or stalemated
+	for result == board.NoWinner {
+		if t.cfg.RolloutDepth > 0 && rolloutMoves == t.cfg.RolloutDepth {
+			result = state.GetResult(state.GetPlayerJustMoved())
+			break
 		}
+		m := t.rolloutMove(state)
+		if m == board.NoMove {
+			result = state.NoMovesResult(state.GetPlayerJustMoved())
+			break
+		}
+		state.MakeMove(m)
+		t.amaf.play(m)
+		movesToRoot++
+		rolloutMoves++
+		if state.IsDrawn() {
+			result = board.Draw
+		}
+	}
 
-		elapsedTime = time.Since(timeData.startTime).Seconds() * 1000 // get elapsed time in ms
+	// the rollout was cut off -> the static evaluation (after resolving captures)
+	// is used as the result of the game. A draw is scored with the contempt
+	resultPlayer := state.GetPlayerJustMoved()
+	score := t.cfg.resultScore(result, resultPlayer)
+	if result == board.NoWinner {
+		score = 1 - t.evaluate(state)
+	}
+	draw := result == board.Draw
+	t.stats.countResult(result, rolloutMoves)
+	backupStart := time.Now()
+	t.stats.rollout += backupStart.Sub(rolloutStart)
+
+	// Backpropagate
+	// backpropagate from the expanded node and work back to the root node
+	t.amaf.backpropagate()
+	for depth := treeDepth; node != nil; depth-- {
+		// Update node with result from POV of node.playerJustMoved
+		nodeScore := score
+		if node.playerJustMoved != resultPlayer {
+			nodeScore = 1 - score
+		}
+		node.Update(nodeScore, draw)
+		// the statistics of the position are shared with its transpositions (UCT over a DAG):
+		// every position on the path of the playout is updated once per node of the path
+		if t.tt != nil {
+			t.tt.update(node, nodeScore)
+		}
+		if node.parent != noNode {
+			t.history.Add(node.playerJustMoved, node.move, nodeScore)
+		}
+		if t.cfg.RAVE {
+			t.amaf.update(t.arena, node, depth, resultPlayer, score)
+		}
+		node.updateProof(t.arena)
+		if t.cfg.ImplicitMinimax {
+			node.updateMinimax(t.arena, &t.cfg)
+		}
+		if node.proof == ProvenWin && node.parent != noNode {
+			t.killers.Add(depth-1, node.move)
+		}
+		node = t.arena.parent(node)
 	}
 
-	// Todo try returning move with highest average score
-	sort.Slice(rootnode.childNodes, func(i, j int) bool {
-		return rootnode.childNodes[i].visits > rootnode.childNodes[j].visits
-	})
-	// above we sort by descending order -> move with most visits is the first element
-	bestMove := rootnode.childNodes[0]
-	return uctResult{state: state, move: originMove, wins: bestMove.wins, visits: bestMove.visits, totalSimulations: simulations}
+	// Revert all the made moves
+	for j := 0; j < movesToRoot; j++ {
+		state.TakeMove()
+	}
+	t.simulations++
+	t.stats.backup += time.Since(backupStart)
 }
 
-type timeInfo struct {
-	startTime time.Time
-	stopTime  int
-	isTimeSet bool
+// reset prepares the tree for a search from the given position. If the position
+// can be reached from the previous root in one or two plies, the subtree of that
+// position becomes the new root, otherwise a new tree is started. The random
+// numbers of the search are generated from seed. If rootMoves is not nil, a new
+// tree is started whose root only has these moves
+func (t *tree) reset(state *board.ChessBoard, cfg Config, capacity int, seed int64, rootMoves []int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rng == nil {
+		t.rng = rand.New(rand.NewSource(seed))
+	} else {
+		t.rng.Seed(seed)
+	}
+	t.cfg = cfg
+	t.stats = playoutStats{start: time.Now()}
+	t.simulations = 0
+	t.depthSum = 0
+	t.selDepth = 0
+
+	if t.arena == nil || t.arena.capacity != capacity {
+		t.arena = newArena(capacity)
+		t.root = nil
+	}
+
+	if t.root != nil && !t.restricted && rootMoves == nil {
+		if node := t.findPosition(t.root, state, 2); node != nil {
+			// the rest of the old tree is no longer reachable
+			t.arena.releaseExcept(t.root, node)
+			node.parent = noNode
+			node.sibling = noNode
+			t.root = node
+			t.state = *state
+			return
+		}
+	}
+
+	t.arena.reset()
+	t.root = createRootNode(t.arena, state)
+	t.state = *state
+	t.restricted = rootMoves != nil
+	if t.restricted {
+		t.root.untriedMoves = append(t.root.untriedMoves[:0], rootMoves...)
+	}
 }
 
-type uctArg struct {
-	state    *board.ChessBoard
-	move     int
-	timeData timeInfo
+// resizeTable replaces the transposition table of the tree if its size changed
+func (t *tree) resizeTable(size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if (t.tt == nil && size == 0) || (t.tt != nil && t.tt.size == size) {
+		return
+	}
+	t.tt = newTranspositionTable(size)
+	// the entries of the old table are no longer valid
+	t.root.forEach(t.arena, func(n *Node) { n.entry = nil })
 }
 
-func worker(jobs <-chan uctArg, results chan<- uctResult) {
-	for uctArguments := range jobs {
-		results <- uct(uctArguments.state, uctArguments.move, uctArguments.timeData)
+// findPosition looks for a node (up to maxDepth plies below node) whose position matches state
+func (t *tree) findPosition(node *Node, state *board.ChessBoard, maxDepth int) *Node {
+	if t.state.PosKey() == state.PosKey() {
+		return node
+	}
+	if maxDepth == 0 {
+		return nil
 	}
+
+	for child := t.arena.child(node); child != nil; child = t.arena.sibling(child) {
+		t.state.MakeMove(child.move)
+		found := t.findPosition(child, state, maxDepth-1)
+		t.state.TakeMove()
+		if found != nil {
+			return found
+		}
+	}
+	return nil
 }
 
-// todo need to return pointer to state!!!
-// GetEngineMoveFast returns the best move found by the UCT (computed in parallel)
-func GetEngineMoveFast(state *board.ChessBoard, info *board.SearchInfo) (move int, score float64, totalSim int) {
-	availableMoves := state.GetMoves()
+// worker runs playouts until ctx is cancelled or maxPlayouts playouts are done (0 - no
+// limit). Once the result of the root position is proven there is nothing left to
+// search and all workers are stopped. With a playout limit only this worker stops,
+// so the number of playouts of the other trees does not depend on the timing
+func (t *tree) worker(ctx context.Context, stop context.CancelFunc, maxPlayouts int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for ctx.Err() == nil && (maxPlayouts == 0 || t.simulations < maxPlayouts) {
+		t.mu.Lock()
+		t.playout()
+		proven := t.root.proof != Unproven
+		t.mu.Unlock()
+
+		if proven && maxPlayouts > 0 {
+			return
+		}
+		if proven {
+			stop()
+		}
+	}
+}
+
+// RootMove holds the statistics of a root move merged from all trees
+type RootMove struct {
+	Move         int
+	Wins         float64 // wins from the point of view of the side to move at the root (a draw counts with the draw score)
+	DecisiveWins float64 // wins of the playouts that did not end in a draw
+	Draws        float64 // number of playouts that ended in a draw
+	Visits       float64
+	Proof        int // proof status from the point of view of the side to move at the root
+	ProofPlies   int
+}
+
+// RootMoves returns the merged statistics of all root moves searched so far.
+// It is safe to call while a search is running
+func (s *Searcher) RootMoves() []RootMove {
+	rootMoves := make([]RootMove, 0)
+	index := make(map[int]int)
+	for _, t := range s.trees {
+		t.mu.Lock()
+		if t.root != nil {
+			for child := t.arena.child(t.root); child != nil; child = t.arena.sibling(child) {
+				idx, ok := index[child.move]
+				if !ok {
+					idx = len(rootMoves)
+					index[child.move] = idx
+					rootMoves = append(rootMoves, RootMove{Move: child.move})
+				}
+				rootMoves[idx].Wins += child.wins
+				rootMoves[idx].DecisiveWins += child.decisiveWins
+				rootMoves[idx].Draws += child.draws
+				rootMoves[idx].Visits += child.visits
+				if child.proof != Unproven {
+					// a proof is valid in every tree
+					rootMoves[idx].Proof = child.proof
+					rootMoves[idx].ProofPlies = child.proofPlies
+				}
+			}
+		}
+		t.mu.Unlock()
+	}
+	return rootMoves
+}
+
+// Search searches the given position until ctx is cancelled, the time set in info
+// runs out or info.MaxNodes playouts are done. When pondering or in infinite mode the
+// time limit is ignored and only cancelling ctx stops the search.
+// Every tree draws its random numbers from its own generator seeded from info.Seed.
+// With a seed and a playout limit (and no time limit) the search is reproducible: the
+// trees of earlier searches are discarded and the playouts are split evenly between
+// the workers, so the same position always gives the same move and statistics.
+// With info.SearchMoves only these root moves are searched in new trees
+func (s *Searcher) Search(ctx context.Context, state *board.ChessBoard, info *board.SearchInfo) SearchResult {
+	availableMoves := state.RootMoves(info.SearchMoves)
 	numMoves := len(availableMoves)
+	var rootMoves []int
+	if numMoves < len(state.GetMoves()) {
+		rootMoves = availableMoves
+	}
 
 	if numMoves == 0 {
 		panic("Game is already over, can't get engine move for a finished game!")
 	} else if numMoves == 1 {
 		// no clue what the score is here since we haven't actually searched the move
-		return availableMoves[0], 0.5, 0
+		return SearchResult{Move: availableMoves[0], PonderMove: board.NoMove, Score: 0.5}
 	}
 
-	// todo return whole node from mcts and then send it down on the jobs for the next run
-	// todo send a cut-off of depth -> even if game is not over - evaluate position and return evaluation as if it's a proper result
-	// todo make sure to take care of quescent position i.e. do not stop immediatelly but on a quiet position
-	// todo add move ordering i.e. more promissing moves might get more iterations ??
-	// create channels to share data between goroutines
-	jobs := make(chan uctArg, numMoves)
-	results := make(chan uctResult, numMoves)
+	timed := info.TimeSet && !info.Ponder && !info.Infinite
+	maxRobust := s.Config.MoveSelection == MaxRobustChildName
+	allotted := time.Duration(info.StopTime) * time.Millisecond
+	softStop := info.StartTime.Add(allotted)
+	if timed {
+		var cancel context.CancelFunc
+		stopTime := softStop
+		if maxRobust {
+			// the search may go on for a while until the best and the most visited move agree
+			stopTime = stopTime.Add(time.Duration(float64(allotted) * MaxRobustExtension))
+		}
+		ctx, cancel = context.WithDeadline(ctx, stopTime)
+		defer cancel()
+	}
 
-	// spawn workers ready to process data
-	for _i := 0; _i < numMoves; _i++ {
-		go worker(jobs, results)
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	if timed && maxRobust {
+		go s.stopWhenMaxRobust(ctx, stop, softStop)
 	}
 
-	timeData := timeInfo{startTime: info.StartTime, stopTime: info.StopTime, isTimeSet: info.TimeSet}
-	bestMove := rankedMove{move: -1, score: 1.1}
+	seed := info.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if info.Seed != 0 || len(s.trees) != s.threads() {
+		s.Clear()
+	}
 
-	for _, move := range availableMoves {
-		// create a copy of the board in order to be sent to the goroutine
-		b := *state
+	cfg := s.Config
+	cfg.setContempt(state)
 
-		jobs <- uctArg{
-			state:    &b,
-			move:     move,
-			timeData: timeData,
-		}
+	var wg sync.WaitGroup
+	capacity := s.Config.TreeMemory * 1024 * 1024 / NodeBytes / len(s.trees)
+	for i, t := range s.trees {
+		t.reset(state, cfg, capacity, seed+int64(i), rootMoves)
+		t.resizeTable(s.Config.HashSize * 1024 * 1024 / len(s.trees))
+		wg.Add(1)
+		go t.worker(ctx, stop, workerPlayouts(info.MaxNodes, len(s.trees), i), &wg)
+	}
+	wg.Wait()
+
+	totalSimulations := 0
+	for _, t := range s.trees {
+		t.mu.Lock()
+		t.stats.end = time.Now()
+		totalSimulations += t.simulations
+		t.mu.Unlock()
 	}
 
-	close(jobs) // close jobs channel
-
-	var result uctResult
-	var scoreValue float64
-	var totalSimulations int
-	for _i := 0; _i < numMoves; _i++ {
-		result = <-results
-		scoreValue = result.wins / result.visits
+	bestMove := bestRootMove(s.RootMoves(), s.Config.MoveSelection)
+	if bestMove.Move == board.NoMove {
+		bestMove.Move = availableMoves[0]
+	}
 
-		fmt.Printf("Move: %s: %.3f -> %.1f / %.0f (%d)\n",
-			board.PrintMove(result.move), scoreValue, result.wins, result.visits, result.totalSimulations)
-		// here the move_score refers to the best enemy reply
-		// therefore we want to minimize that i.e. chose the move
-		// which leads to the lowest scored best enemy reply
-		if scoreValue < bestMove.score {
-			bestMove.score = scoreValue
-			bestMove.move = result.move
-		}
-		totalSimulations += result.totalSimulations
+	ponderMove := board.NoMove
+	if pv := s.principalVariation(bestMove.Move); len(pv) > 1 {
+		ponderMove = pv[1]
 	}
-	fmt.Printf("Total simulations done: %d\n", totalSimulations)
 
-	return bestMove.move, bestMove.score, totalSimulations
+	return SearchResult{
+		Move:        bestMove.Move,
+		PonderMove:  ponderMove,
+		Score:       bestMove.Score(cfg.drawScore(state.Side)),
+		Simulations: totalSimulations,
+	}
 }
 
-func isImmediateResult(state *board.ChessBoard, move int) (isResult bool, result uctResult) {
-	enemy := state.GetEnemy(state.GetPlayerJustMoved())
-	gameResult := state.GetResult(enemy)
-
-	if gameResult != board.NoWinner {
-		result = uctResult{state: state, move: move, wins: float64(gameResult), visits: 1.0}
-		isResult = true
+// workerPlayouts returns the share of the playout limit of worker i (0 - no limit)
+func workerPlayouts(maxNodes uint64, workers, i int) int {
+	if maxNodes == 0 {
+		return 0
 	}
-
-	return
+	share := maxNodes / uint64(workers)
+	if uint64(i) < maxNodes%uint64(workers) {
+		share++
+	}
+	if share == 0 {
+		// every worker does at least one playout, a limit of 0 would mean no limit
+		share = 1
+	}
+	return int(share)
 }
 
-// GetEngineMove returns the best move found by the UCT
-// func GetEngineMove(state board.ChessBoard, simulations int) int {
-// 	availableMoves := state.GetMoves()
-
-// 	if len(availableMoves) == 0 {
-// 		panic("Game is already over, can't get engine move for a finished game!")
-// 	} else if len(availableMoves) == 1 {
-// 		return availableMoves[0]
-// 	}
-
-// 	simPerMove := simulations / len(availableMoves)
-
-// 	bestMove := rankedMove{move: -1, score: 1.1}
-// 	for _, move := range availableMoves {
-// 		b := state // todo does this copy ? or points
-
-// 		mScore := uct(b, move, simPerMove)
-// 		scoreValue := mScore.wins / mScore.visits
+// rootMoveBefore orders the root moves from the best to the worst. A proven win is
+// always best (the quickest one), proven losses are worst (the slowest one is the
+// least bad). Otherwise the moves are ordered by the move selection policy
+func rootMoveBefore(a, b RootMove, selection string) bool {
+	if a.Proof == ProvenWin || b.Proof == ProvenWin {
+		if a.Proof == b.Proof {
+			return a.ProofPlies < b.ProofPlies
+		}
+		return a.Proof == ProvenWin
+	}
+	if a.Proof == ProvenLoss || b.Proof == ProvenLoss {
+		if a.Proof == b.Proof {
+			return a.ProofPlies > b.ProofPlies
+		}
+		return b.Proof == ProvenLoss
+	}
+	return selectionScore(a, selection) > selectionScore(b, selection)
+}
 
-// 		fmt.Printf("Move: %d: %.3f -> %f / %f\n", move, scoreValue, mScore.wins, mScore.visits)
-// 		// here the move_score refers to the best enemy reply
-// 		// therefore we want to minimize that i.e. chose the move
-// 		// which leads to the lowest scored best enemy reply
-// 		if scoreValue < bestMove.score {
-// 			bestMove.score = scoreValue
-// 			bestMove.move = move
-// 		}
-// 		// take move since b here is not a copy but points to the same position as state
-// 		b.TakeMove()
-// 	}
-// 	return bestMove.move
-// }
+// bestRootMove returns the move to play (Move is board.NoMove if there are no root moves)
+func bestRootMove(rootMoves []RootMove, selection string) RootMove {
+	best := RootMove{Move: board.NoMove}
+	for i, rootMove := range rootMoves {
+		if i == 0 || rootMoveBefore(rootMove, best, selection) {
+			best = rootMove
+		}
+	}
+	return best
+}
@@ -0,0 +1,33 @@
+package uct
+
+import (
+	"context"
+	"slinky/board"
+	"testing"
+)
+
+// TestSearchMoves checks that only the searchmoves are searched and that the restricted
+// tree is not reused by the next search
+func TestSearchMoves(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	searchMoves := []int{pos.ParseMove("d1d2"), pos.ParseMove("g1f1")}
+
+	searcher := NewSearcher()
+	searcher.Config.Threads = 2
+	result := searcher.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 200, SearchMoves: searchMoves})
+	if result.Move != searchMoves[0] && result.Move != searchMoves[1] {
+		t.Errorf("expected one of the searchmoves, got %s", board.PrintMove(result.Move))
+	}
+	for _, rootMove := range searcher.RootMoves() {
+		if rootMove.Move != searchMoves[0] && rootMove.Move != searchMoves[1] {
+			t.Errorf("the root move %s was searched", board.PrintMove(rootMove.Move))
+		}
+	}
+
+	result = searcher.Search(context.Background(), &pos, &board.SearchInfo{MaxNodes: 2000})
+	if board.PrintMove(result.Move) != "d1d8" {
+		t.Errorf("expected the mate d1d8 without searchmoves, got %s", board.PrintMove(result.Move))
+	}
+}
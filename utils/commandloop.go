@@ -1,25 +1,21 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"slinky/board"
-	"slinky/uct"
+	"slinky/engine"
+	"slinky/eval"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func runEngine(pos *board.ChessBoard, info *board.SearchInfo, moveTime int) (gameOver bool) {
+func runEngine(e *engine.Engine, pos *board.ChessBoard, info *board.SearchInfo, moveTime int) (gameOver bool) {
 	if pos.GetResult(pos.PlayerJustMoved) == board.NoWinner {
-		info.StartTime = time.Now()
-
-		if moveTime != 0 {
-			info.TimeSet = true
-			info.StopTime = moveTime
-		}
-
-		// board.SearchPosition(pos, info)
-		engineMove, _, _ := uct.GetEngineMoveFast(pos, info)
+		limits := engine.Limits{MoveTime: time.Duration(moveTime) * time.Millisecond}
+		engineMove, _ := SearchPosition(context.Background(), e, pos, info, limits)
 		fmt.Printf("Engine move is %s\n", board.PrintMove(engineMove))
 		pos.MakeMove(engineMove)
 		fmt.Println(pos)
@@ -35,6 +31,8 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 
 	moveTime := 3000 // 3 seconds move time
 	move := board.NoMove
+	e := engine.New()
+	e.Seed = info.Seed
 
 	pos.ParseFen(board.StartFen)
 
@@ -60,6 +58,12 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 			fmt.Printf("time x - set thinking time to x seconds (depth still applies if set)\n")
 			fmt.Printf("view - show current depth and moveTime settings\n")
 			fmt.Printf("showline - show current move line so far\n")
+			fmt.Printf("selfplay option a b [games] [movetime] - play a match between option values a and b\n")
+			fmt.Printf("evalbreakdown - show every term of the static evaluation\n")
+			fmt.Printf("dumptree [json|dot] [depth x] [visits x] [file x] - export the tree of the last search\n")
+			fmt.Printf("stats - show the statistics of the last search\n")
+			fmt.Printf("personality x - set the playing style to x (%s)\n", strings.Join(engine.PersonalityNames, ", "))
+			fmt.Printf("elo x - limit the strength to a rating of x (%d-%d), elo off for full strength\n", engine.MinElo, engine.MaxElo)
 			fmt.Printf("getmoves - show all moves")
 			fmt.Printf("playout - force engine to play position till end")
 			fmt.Printf("getfen - print fen of current position")
@@ -68,6 +72,44 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 			continue
 		}
 
+		if strings.TrimSpace(command) == "stats" {
+			stats := e.Stats()
+			fmt.Print(&stats)
+			continue
+		}
+
+		if strings.HasPrefix(command, "personality") {
+			if err := setPersonality(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(command, "elo") {
+			if err := setElo(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.Contains(command, "dumptree") {
+			if err := dumpTree(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.Contains(command, "evalbreakdown") {
+			breakdown := eval.Explain(pos)
+			fmt.Println(&breakdown)
+			continue
+		}
+
+		if strings.Contains(command, "selfplay") {
+			SelfPlay(command)
+			continue
+		}
+
 		if strings.Contains(command, "setboard") {
 			startStr := "setboard "
 			fen := board.RemoveStringToTheLeftOfMarker(command, startStr)
@@ -86,7 +128,7 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 		}
 
 		if strings.Contains(command, "playout") {
-			for !runEngine(pos, info, moveTime) {
+			for !runEngine(e, pos, info, moveTime) {
 				winner := ""
 				switch pos.GetResult(pos.PlayerJustMoved) {
 				case board.Win:
@@ -140,7 +182,7 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 		}
 
 		if strings.Contains(command, "force") {
-			res := runEngine(pos, info, moveTime)
+			res := runEngine(e, pos, info, moveTime)
 			if res == true {
 				fmt.Println("Game is over")
 			}
@@ -157,7 +199,7 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 		}
 
 		if strings.Contains(command, "showline") {
-			fmt.Println(board.GetBookMove(pos))
+			fmt.Println(board.GetBookMove(pos, rand.Intn))
 			continue
 		}
 
@@ -175,12 +217,13 @@ func CommandLoop(pos *board.ChessBoard, info *board.SearchInfo, cmds []string) {
 		}
 
 		if strings.Contains(command, "new") {
+			e.Clear()
 			pos.ParseFen(board.StartFen)
 			continue
 		}
 
 		if strings.Contains(command, "go") {
-			res := runEngine(pos, info, moveTime)
+			res := runEngine(e, pos, info, moveTime)
 			if res == true {
 				fmt.Println("Game is over")
 			}
@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"slinky/board"
-	"slinky/uct"
+	"slinky/engine"
+	"slinky/eval"
 	"strconv"
 	"strings"
 	"time"
@@ -21,23 +24,16 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 	engineSide := board.Both
 	move := board.NoMove
 	playout := false // forces engine to play until game is over
+	e := engine.New()
+	e.Seed = info.Seed
 
 	// engineSide = board.Black
 	pos.ParseFen(board.StartFen)
 
-	command := ""
-
 	for {
 		if (pos.Side == engineSide || playout == true) && pos.GetResult(pos.PlayerJustMoved) == board.NoWinner {
-			info.StartTime = time.Now()
-
-			if moveTime != 0 {
-				info.TimeSet = true
-				info.StopTime = moveTime
-			}
-
-			// board.SearchPosition(pos, info)
-			engineMove, _, _ := uct.GetEngineMoveFast(pos, info)
+			limits := engine.Limits{MoveTime: time.Duration(moveTime) * time.Millisecond}
+			engineMove, _ := SearchPosition(context.Background(), e, pos, info, limits)
 			fmt.Printf("Engine move is %s\n", board.PrintMove(engineMove))
 			pos.MakeMove(engineMove)
 			fmt.Println(pos)
@@ -47,7 +43,11 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 			}
 		}
 
-		command, _ = GetInput("\nSlinky > ")
+		command, err := GetInput("\nSlinky > ")
+		if err != nil {
+			info.Quit = true
+			break
+		}
 		if len(command) < 2 {
 			continue
 		}
@@ -66,6 +66,12 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 			fmt.Printf("time x - set thinking time to x seconds (depth still applies if set)\n")
 			fmt.Printf("view - show current depth and moveTime settings\n")
 			fmt.Printf("showline - show current move line so far\n")
+			fmt.Printf("selfplay option a b [games] [movetime] - play a match between option values a and b\n")
+			fmt.Printf("evalbreakdown - show every term of the static evaluation\n")
+			fmt.Printf("dumptree [json|dot] [depth x] [visits x] [file x] - export the tree of the last search\n")
+			fmt.Printf("stats - show the statistics of the last search\n")
+			fmt.Printf("personality x - set the playing style to x (%s)\n", strings.Join(engine.PersonalityNames, ", "))
+			fmt.Printf("elo x - limit the strength to a rating of x (%d-%d), elo off for full strength\n", engine.MinElo, engine.MaxElo)
 			fmt.Printf("getmoves - show all moves")
 			fmt.Printf("playout - force engine to play position till end")
 			fmt.Printf("** note ** - to reset time and depth, set to 0\n")
@@ -73,6 +79,44 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 			continue
 		}
 
+		if strings.TrimSpace(command) == "stats" {
+			stats := e.Stats()
+			fmt.Print(&stats)
+			continue
+		}
+
+		if strings.HasPrefix(command, "personality") {
+			if err := setPersonality(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(command, "elo") {
+			if err := setElo(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.Contains(command, "dumptree") {
+			if err := dumpTree(command, e); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		}
+
+		if strings.Contains(command, "evalbreakdown") {
+			breakdown := eval.Explain(pos)
+			fmt.Println(&breakdown)
+			continue
+		}
+
+		if strings.Contains(command, "selfplay") {
+			SelfPlay(command)
+			continue
+		}
+
 		if strings.Contains(command, "setboard") {
 			engineSide = board.Both
 			startStr := "setboard "
@@ -131,7 +175,7 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 		}
 
 		if strings.Contains(command, "showline") {
-			fmt.Println(board.GetBookMove(pos))
+			fmt.Println(board.GetBookMove(pos, rand.Intn))
 			continue
 		}
 
@@ -150,6 +194,7 @@ func ConsoleLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 
 		if strings.Contains(command, "new") {
 			engineSide = board.Black
+			e.Clear()
 			pos.ParseFen(board.StartFen)
 			continue
 		}
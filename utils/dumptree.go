@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slinky/engine"
+	"slinky/uct"
+	"strings"
+)
+
+// Defaults of the dumptree command
+const (
+	dumpTreeDepth  = 3
+	dumpTreeVisits = 1
+)
+
+// dumpTree handles the command 'dumptree [json|dot] [depth <n>] [visits <n>] [file <path>]'.
+// It exports the tree of the last (or running) UCT search down to the given depth,
+// leaving out the nodes with fewer visits. Without a file the tree is written to stdout
+func dumpTree(line string, e *engine.Engine) error {
+	opts := uct.ExportOptions{MaxDepth: dumpTreeDepth, MinVisits: dumpTreeVisits}
+	if strings.Contains(line, " depth ") {
+		opts.MaxDepth = goParameter(line, "depth")
+	}
+	if strings.Contains(line, " visits ") {
+		opts.MinVisits = float64(goParameter(line, "visits"))
+	}
+
+	root := e.UCT.Tree(opts)
+	if root == nil {
+		return fmt.Errorf("dumptree: no search tree, run a search with the %s engine first", engine.MCTS)
+	}
+
+	var w io.Writer = os.Stdout
+	if path := commandArgument(line, "file"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if strings.Contains(line+" ", " dot ") {
+		return uct.WriteDOT(w, root)
+	}
+	return uct.WriteJSON(w, root)
+}
+
+// commandArgument returns the word following name in the command ("" if it is missing)
+func commandArgument(line, name string) string {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == name {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
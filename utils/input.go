@@ -3,15 +3,39 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
+var (
+	inputOnce  sync.Once
+	inputLines chan string
+)
+
+// InputLines returns the channel that is fed by the dedicated stdin reader.
+// The reader is started on first use and the channel is closed when stdin reaches EOF
+func InputLines() <-chan string {
+	inputOnce.Do(func() {
+		inputLines = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				inputLines <- scanner.Text()
+			}
+			close(inputLines)
+		}()
+	})
+	return inputLines
+}
+
 // GetInput Gets input from user until '\n'. It accepts a prompt message as argument
 func GetInput(prompt string) (input string, err error) {
-	fmt.Printf(prompt)
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	inputStr := scanner.Text()
+	fmt.Print(prompt)
+	inputStr, ok := <-InputLines()
+	if !ok {
+		return "", io.EOF
+	}
 
-	return inputStr, scanner.Err()
+	return inputStr, nil
 }
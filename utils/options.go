@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"slinky/ab"
+	"slinky/board"
+	"slinky/engine"
+	"slinky/uct"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uciOption is an engine option that the GUI can change with the 'setoption' command
+type uciOption struct {
+	name string
+	kind string // check, spin, combo, button or string
+	def  string
+	min  int // only used for spin options
+	max  int // only used for spin options
+	vars []string
+	set  func(o *uciOption, value string)
+}
+
+// String returns the option in the format expected by the 'uci' command
+func (o *uciOption) String() string {
+	line := fmt.Sprintf("option name %s type %s", o.name, o.kind)
+	if o.kind != "button" {
+		line += fmt.Sprintf(" default %s", o.def)
+	}
+	if o.kind == "spin" {
+		line += fmt.Sprintf(" min %d max %d", o.min, o.max)
+	}
+	for _, v := range o.vars {
+		line += fmt.Sprintf(" var %s", v)
+	}
+	return line
+}
+
+// spinValue converts value to an int clamped to the limits of the option
+func (o *uciOption) spinValue(value string) int {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		v, _ = strconv.Atoi(o.def)
+	}
+	if v < o.min {
+		v = o.min
+	} else if v > o.max {
+		v = o.max
+	}
+	return v
+}
+
+// uciOptions returns all options supported by the engine together with the functions that apply them
+func uciOptions(info *board.SearchInfo, e *engine.Engine) []*uciOption {
+	searcher := e.UCT
+	options := []*uciOption{
+		{name: "Engine", kind: "combo", def: engine.MCTS, vars: engine.Names,
+			set: func(o *uciOption, value string) { e.Algorithm = comboValue(o, value) }},
+		{name: "Ponder", kind: "check", def: "false",
+			set: func(o *uciOption, value string) {}},
+		{name: "OwnBook", kind: "check", def: "true",
+			set: func(o *uciOption, value string) { e.OwnBook = value == "true" }},
+		{name: "Threads", kind: "spin", def: "0", min: 0, max: 1024,
+			set: func(o *uciOption, value string) { searcher.Config.Threads = o.spinValue(value) }},
+		{name: "Seed", kind: "spin", def: strconv.FormatInt(e.Seed, 10), min: 0, max: math.MaxInt64,
+			set: func(o *uciOption, value string) { e.Seed = int64(o.spinValue(value)) }},
+		{name: "Move Overhead", kind: "spin", def: "30", min: 0, max: 5000,
+			set: func(o *uciOption, value string) {
+				e.MoveOverhead = time.Duration(o.spinValue(value)) * time.Millisecond
+			}},
+		{name: "Hash", kind: "spin", def: "0", min: 0, max: 65536,
+			set: func(o *uciOption, value string) {
+				// the tree search runs without a transposition table by default, the
+				// alpha-beta search always needs one
+				searcher.Config.HashSize = o.spinValue(value)
+				e.AB.HashSize = o.spinValue(value)
+				if e.AB.HashSize == 0 {
+					e.AB.HashSize = ab.DefaultHashSize
+				}
+			}},
+		{name: "TreeMemory", kind: "spin", def: "256", min: 1, max: 65536,
+			set: func(o *uciOption, value string) { searcher.Config.TreeMemory = o.spinValue(value) }},
+		{name: "MultiPV", kind: "spin", def: "1", min: 1, max: board.MaxPositionMoves,
+			set: func(o *uciOption, value string) { e.MultiPV = o.spinValue(value) }},
+		{name: "UCI_ShowWDL", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { info.ShowWDL = value == "true" }},
+		{name: "UCI_LimitStrength", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { e.LimitStrength = value == "true" }},
+		{name: "UCI_Elo", kind: "spin", def: strconv.Itoa(engine.DefaultElo), min: engine.MinElo, max: engine.MaxElo,
+			set: func(o *uciOption, value string) { e.Elo = o.spinValue(value) }},
+		{name: "Personality", kind: "combo", def: engine.DefaultPersonality, vars: engine.PersonalityNames,
+			set: func(o *uciOption, value string) { e.SetPersonality(comboValue(o, value)) }},
+		{name: "RolloutPolicy", kind: "combo", def: uct.UniformPolicyName, vars: uct.PolicyNames,
+			set: func(o *uciOption, value string) { e.SetRolloutPolicy(uct.NewRolloutPolicy(value)) }},
+		{name: "RolloutDepth", kind: "spin", def: "0", min: 0, max: board.MaxGameMoves,
+			set: func(o *uciOption, value string) { searcher.Config.RolloutDepth = o.spinValue(value) }},
+		{name: "Selection", kind: "combo", def: uct.UCB1SelectionName, vars: uct.SelectionNames,
+			set: func(o *uciOption, value string) { searcher.Config.Selection = comboValue(o, value) }},
+		{name: "Prior", kind: "combo", def: uct.UniformPriorName, vars: uct.PriorNames,
+			set: func(o *uciOption, value string) { searcher.Config.Prior = uct.NewPrior(value) }},
+		{name: "Exploration", kind: "spin", def: "141", min: 0, max: 1000,
+			set: func(o *uciOption, value string) { searcher.Config.Exploration = float64(o.spinValue(value)) / 100 }},
+		{name: "FPU", kind: "spin", def: "20", min: 0, max: 100,
+			set: func(o *uciOption, value string) { searcher.Config.FPUReduction = float64(o.spinValue(value)) / 100 }},
+		{name: "RAVE", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { searcher.Config.RAVE = value == "true" }},
+		{name: "RAVEEquivalence", kind: "spin", def: "250", min: 1, max: 100000,
+			set: func(o *uciOption, value string) { searcher.Config.RAVEEquivalence = float64(o.spinValue(value)) }},
+		{name: "ProgressiveWidening", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { searcher.Config.Widening = value == "true" }},
+		{name: "WideningExponent", kind: "spin", def: "50", min: 0, max: 100,
+			set: func(o *uciOption, value string) { searcher.Config.WideningExponent = float64(o.spinValue(value)) / 100 }},
+		{name: "MoveSelection", kind: "combo", def: uct.RobustChildName, vars: uct.MoveSelectionNames,
+			set: func(o *uciOption, value string) { searcher.Config.MoveSelection = comboValue(o, value) }},
+		{name: "RolloutMinimax", kind: "spin", def: "0", min: 0, max: 2,
+			set: func(o *uciOption, value string) { searcher.Config.RolloutMinimax = o.spinValue(value) }},
+		{name: "ImplicitMinimax", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { searcher.Config.ImplicitMinimax = value == "true" }},
+		{name: "MinimaxWeight", kind: "spin", def: "30", min: 0, max: 100,
+			set: func(o *uciOption, value string) { searcher.Config.MinimaxWeight = float64(o.spinValue(value)) / 100 }},
+		{name: "Contempt", kind: "spin", def: "0", min: -200, max: 200,
+			set: func(o *uciOption, value string) { e.SetContempt(o.spinValue(value)) }},
+		{name: "ContemptPhase", kind: "check", def: "false",
+			set: func(o *uciOption, value string) { e.SetContemptPhase(value == "true") }},
+	}
+
+	for _, option := range options {
+		option.set(option, option.def)
+	}
+	// the defaults are not chosen by the user, they must not override the personality
+	e.ResetStyle()
+	return options
+}
+
+// comboValue returns the variant of a combo option matching value, unknown values select the default
+func comboValue(o *uciOption, value string) string {
+	for _, v := range o.vars {
+		if strings.EqualFold(v, value) {
+			return v
+		}
+	}
+	return o.def
+}
+
+// parseSetOption parses UCI setoption command and applies the value to the matching option
+// the expected format is 'setoption name <id> [value <x>]'
+func parseSetOption(line string, options []*uciOption) {
+	nameStr := board.RemoveStringToTheLeftOfMarker(line, "name ")
+	name := strings.TrimSpace(board.RemoveStringToTheRightOfMarker(nameStr, " value"))
+	value := ""
+	if strings.Contains(line, " value ") {
+		value = strings.TrimSpace(board.RemoveStringToTheLeftOfMarker(line, " value "))
+	}
+
+	if option := findOption(options, name); option != nil {
+		option.set(option, value)
+		return
+	}
+	fmt.Printf("info string unknown option %s\n", name)
+}
+
+// findOption returns the option with the given name. The comparison ignores case and spaces
+func findOption(options []*uciOption, name string) *uciOption {
+	name = strings.ReplaceAll(name, " ", "")
+	for _, option := range options {
+		if strings.EqualFold(strings.ReplaceAll(option.name, " ", ""), name) {
+			return option
+		}
+	}
+	return nil
+}
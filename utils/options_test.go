@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"slinky/ab"
+	"slinky/board"
+	"slinky/engine"
+	"slinky/uct"
+	"testing"
+)
+
+// TestPersonalityOptions checks that the Personality option is the base of the style
+// and that the style options set by the GUI take precedence over it in any order
+func TestPersonalityOptions(t *testing.T) {
+	var info board.SearchInfo
+	e := engine.New()
+	options := uciOptions(&info, e)
+
+	parseSetOption("setoption name Personality value aggressive", options)
+	if e.UCT.Config.Contempt != 60 || !e.AB.ContemptPhase {
+		t.Errorf("expected the contempt of the preset despite the option defaults, got %d", e.UCT.Config.Contempt)
+	}
+	if _, ok := e.UCT.Config.Policy.(uct.TacticalPolicy); !ok {
+		t.Errorf("expected the tactical rollout policy of the preset, got %T", e.UCT.Config.Policy)
+	}
+
+	parseSetOption("setoption name Contempt value -20", options)
+	parseSetOption("setoption name ContemptPhase value false", options)
+	parseSetOption("setoption name Personality value solid", options)
+	if e.UCT.Config.Contempt != -20 || e.AB.Contempt != -20 || e.UCT.Config.ContemptPhase {
+		t.Errorf("expected the contempt set before the preset to be kept, got %d", e.UCT.Config.Contempt)
+	}
+	if _, ok := e.UCT.Config.Policy.(uct.CapturePolicy); !ok {
+		t.Errorf("expected the capture rollout policy of the preset, got %T", e.UCT.Config.Policy)
+	}
+
+	parseSetOption("setoption name RolloutPolicy value greedy", options)
+	if _, ok := e.UCT.Config.Policy.(uct.GreedyPolicy); !ok {
+		t.Errorf("expected the rollout policy set after the preset, got %T", e.UCT.Config.Policy)
+	}
+}
+
+// TestHashOption checks that the tree search runs without a transposition table by default
+// while the alpha-beta search keeps its own
+func TestHashOption(t *testing.T) {
+	var info board.SearchInfo
+	e := engine.New()
+	options := uciOptions(&info, e)
+
+	if e.UCT.Config.HashSize != 0 || e.AB.HashSize != ab.DefaultHashSize {
+		t.Errorf("expected no tree table and a %d MB alpha-beta table by default, got %d and %d",
+			ab.DefaultHashSize, e.UCT.Config.HashSize, e.AB.HashSize)
+	}
+	parseSetOption("setoption name Hash value 64", options)
+	if e.UCT.Config.HashSize != 64 || e.AB.HashSize != 64 {
+		t.Errorf("expected 64 MB tables, got %d and %d", e.UCT.Config.HashSize, e.AB.HashSize)
+	}
+	parseSetOption("setoption name Hash value 0", options)
+	if e.UCT.Config.HashSize != 0 || e.AB.HashSize != ab.DefaultHashSize {
+		t.Errorf("expected the tree table to be disabled, got %d and %d", e.UCT.Config.HashSize, e.AB.HashSize)
+	}
+}
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slinky/board"
+	"slinky/engine"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxSelfPlayPlies    = 400  // number of half moves after which a self-play game is adjudicated as a draw
+	defaultSelfPlay     = 100  // number of games of a match if none is given
+	defaultSelfPlayTime = 1000 // time per move (in milliseconds) if none is given
+	randomOpeningPlies  = 2    // random half moves played after the opening line to vary the games further
+)
+
+// selfPlayOpenings are the opening lines the self-play games start from. Every opening
+// is played twice with the colours swapped, the lines are reused with other random
+// plies once all of them were played
+var selfPlayOpenings = []string{
+	"e2e4 e7e5 g1f3 b8c6 f1b5",
+	"e2e4 e7e5 g1f3 b8c6 f1c4 f8c5",
+	"e2e4 c7c5 g1f3 d7d6 d2d4 c5d4 f3d4 g8f6 b1c3",
+	"e2e4 c7c5 b1c3 b8c6 g2g3",
+	"e2e4 e7e6 d2d4 d7d5 b1c3",
+	"e2e4 c7c6 d2d4 d7d5 e4e5",
+	"e2e4 d7d6 d2d4 g8f6 b1c3 g7g6",
+	"d2d4 d7d5 c2c4 e7e6 b1c3 g8f6",
+	"d2d4 d7d5 c2c4 c7c6 g1f3 g8f6",
+	"d2d4 g8f6 c2c4 g7g6 b1c3 f8g7 e2e4",
+	"d2d4 g8f6 c2c4 e7e6 b1c3 f8b4",
+	"d2d4 g8f6 c2c4 e7e6 g1f3 b7b6",
+	"c2c4 e7e5 b1c3 g8f6 g1f3",
+	"c2c4 c7c5 g1f3 g8f6 b1c3",
+	"g1f3 d7d5 g2g3 g8f6 f1g2",
+	"e2e4 d7d5 e4d5 d8d5 b1c3",
+}
+
+// selfPlayer is one of the two engine configurations playing a self-play match
+type selfPlayer struct {
+	name   string
+	engine *engine.Engine
+	info   board.SearchInfo
+}
+
+// newSelfPlayer creates an engine configuration where option is set to value
+func newSelfPlayer(option, value string) (*selfPlayer, error) {
+	player := &selfPlayer{
+		name:   fmt.Sprintf("%s=%s", option, value),
+		engine: engine.New(),
+	}
+	player.info.GameMode = board.ConsoleMode
+	player.engine.OwnBook = false // the games start from the self-play openings and are decided by the search
+
+	o := findOption(uciOptions(&player.info, player.engine), option)
+	if o == nil {
+		return nil, fmt.Errorf("unknown option %s", option)
+	}
+	o.set(o, value)
+	return player, nil
+}
+
+// openingPosition returns the start position of the games of a pair: the opening line of
+// the pair followed by randomOpeningPlies random moves. Both games of the pair start from
+// the same position since the random moves only depend on the pair
+func openingPosition(pair int) board.ChessBoard {
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+	for _, move := range strings.Fields(selfPlayOpenings[pair%len(selfPlayOpenings)]) {
+		pos.MakeMove(pos.ParseMove(move))
+	}
+
+	rng := rand.New(rand.NewSource(int64(pair)))
+	for ply := 0; ply < randomOpeningPlies; ply++ {
+		moves := pos.GetMoves()
+		if len(moves) == 0 {
+			break
+		}
+		pos.MakeMove(moves[rng.Intn(len(moves))])
+	}
+	return pos
+}
+
+// playGame plays a game between two players from the start position and returns the
+// result from white's point of view
+func playGame(white, black *selfPlayer, start board.ChessBoard, moveTime time.Duration) board.Result {
+	pos := start
+	players := [2]*selfPlayer{white, black}
+
+	for ply := 0; ply < maxSelfPlayPlies; ply++ {
+		if result := pos.GetResult(board.White); result != board.NoWinner {
+			return result
+		}
+
+		player := players[pos.Side]
+		result, err := player.engine.Search(context.Background(), &pos, engine.Limits{MoveTime: moveTime})
+		if err != nil {
+			return board.Draw
+		}
+		pos.MakeMove(result.Move)
+	}
+	return board.Draw
+}
+
+// eloDifference estimates the elo difference between two players from the score (0-1) of the first player
+func eloDifference(score float64) float64 {
+	if score <= 0 {
+		return math.Inf(-1)
+	} else if score >= 1 {
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/score-1)
+}
+
+// SelfPlay plays a match between two engine configurations which differ in a single option
+// and reports the score and the estimated elo difference. The games start from the
+// self-play openings (see openingPosition), each of them played with both colours.
+// the expected format is 'selfplay <option> <valueA> <valueB> [games] [movetime ms]'
+func SelfPlay(line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		fmt.Println("usage: selfplay <option> <valueA> <valueB> [games] [movetime ms]")
+		return
+	}
+
+	games := positiveArgument(fields, 4, defaultSelfPlay)
+	moveTime := positiveArgument(fields, 5, defaultSelfPlayTime)
+
+	playerA, err := newSelfPlayer(fields[1], fields[2])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	playerB, err := newSelfPlayer(fields[1], fields[3])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	wins, draws, losses := 0, 0, 0
+	for game := 0; game < games; game++ {
+		// colours are swapped every game
+		white, black := playerA, playerB
+		if game%2 == 1 {
+			white, black = playerB, playerA
+		}
+		white.engine.Clear()
+		black.engine.Clear()
+
+		start := openingPosition(game / 2)
+		result := playGame(white, black, start, time.Duration(moveTime)*time.Millisecond)
+		if game%2 == 1 {
+			result = board.Win - result
+		}
+
+		switch result {
+		case board.Win:
+			wins++
+		case board.Loss:
+			losses++
+		default:
+			draws++
+		}
+		fmt.Printf("Game %d (%s vs %s): %s +%d =%d -%d\n",
+			game+1, white.name, black.name, resultString(result, game%2 == 1), wins, draws, losses)
+	}
+
+	score := (float64(wins) + float64(draws)/2) / float64(games)
+	fmt.Printf("%s vs %s: +%d =%d -%d score %.3f elo %+.0f\n",
+		playerA.name, playerB.name, wins, draws, losses, score, eloDifference(score))
+}
+
+// positiveArgument returns the positive integer argument at index i of fields or def
+// when it is missing, not a number or not positive
+func positiveArgument(fields []string, i, def int) int {
+	if len(fields) <= i {
+		return def
+	}
+	value, err := strconv.Atoi(fields[i])
+	if err != nil || value <= 0 {
+		fmt.Printf("invalid argument %s, using %d\n", fields[i], def)
+		return def
+	}
+	return value
+}
+
+// resultString returns the game result in PGN notation, result is from the point of view of player A
+func resultString(result board.Result, playerAIsBlack bool) string {
+	if result == board.Draw {
+		return "1/2-1/2"
+	}
+	if (result == board.Win) != playerAIsBlack {
+		return "1-0"
+	}
+	return "0-1"
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "testing"
+
+// TestPositiveArgument checks that missing, invalid and non-positive arguments fall back to the default
+func TestPositiveArgument(t *testing.T) {
+	fields := []string{"selfplay", "Threads", "1", "2", "10", "0", "-5", "abc"}
+	tests := []struct {
+		i, expected int
+	}{
+		{4, 10},
+		{5, 100},
+		{6, 100},
+		{7, 100},
+		{8, 100},
+	}
+	for _, test := range tests {
+		if value := positiveArgument(fields, test.i, 100); value != test.expected {
+			t.Errorf("argument %d: expected %d, got %d", test.i, test.expected, value)
+		}
+	}
+}
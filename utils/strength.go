@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"slinky/engine"
+	"strconv"
+	"strings"
+)
+
+// setPersonality handles the command 'personality <name>' and selects one of the
+// presets of the engine (see engine.Personalities)
+func setPersonality(line string, e *engine.Engine) error {
+	name := commandArgument(line, "personality")
+	if err := e.SetPersonality(name); err != nil {
+		return fmt.Errorf("personality: unknown personality %q, choose one of %s", name, strings.Join(engine.PersonalityNames, ", "))
+	}
+	fmt.Printf("Personality set to %s\n", name)
+	return nil
+}
+
+// setElo handles the command 'elo <rating>' which limits the strength of the engine
+// to the rating. A rating of 0 (or 'elo off') restores the full strength
+func setElo(line string, e *engine.Engine) error {
+	value := commandArgument(line, "elo")
+	if value == "off" || value == "0" {
+		e.LimitStrength = false
+		fmt.Printf("Playing at full strength\n")
+		return nil
+	}
+	elo, err := strconv.Atoi(value)
+	if err != nil || elo < engine.MinElo || elo > engine.MaxElo {
+		return fmt.Errorf("elo: expected a rating between %d and %d or off, got %q", engine.MinElo, engine.MaxElo, value)
+	}
+	e.LimitStrength = true
+	e.Elo = elo
+	limit := engine.Strength(elo)
+	fmt.Printf("Playing at %d Elo: %d playouts, temperature %.2f, blunder rate %.1f%%\n",
+		elo, limit.Playouts, limit.Temperature, 100*limit.BlunderRate)
+	return nil
+}
@@ -1,152 +1,173 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"slinky/board"
-	"slinky/uct"
+	"slinky/engine"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// ParseGo parse UCI go command
+// ParseGo parse UCI go command and returns the limits of the search
 // sample go commange is below
-//            -white time ms -black time  -b/w increment ms -movetime ms
+//
+//	-white time ms -black time  -b/w increment ms -movetime ms
+//
 // go depth 6 wtime 180000 btime 100000 binc 1000 winc 1000 movetime 1000 movestogo 40
-func ParseGo(line string, info *board.SearchInfo, pos *board.ChessBoard) {
-	movesToGo := 15
-	moveTime := -1
-	timeInt := -1
-	inc := 0
-	info.TimeSet = false
-
-	if strings.Contains(line, "infinite") {
-
-	}
-
-	if strings.Contains(line, "binc") && pos.Side == board.Black {
-		incStr1 := board.RemoveStringToTheLeftOfMarker(line, "binc ")
-		incStr2 := board.RemoveStringToTheRightOfMarker(incStr1, " ")
-		inc, _ = strconv.Atoi(incStr2)
-	}
+func ParseGo(line string, info *board.SearchInfo, pos *board.ChessBoard) engine.Limits {
+	info.Infinite = strings.Contains(line, "infinite")
+	info.Ponder = strings.Contains(line, "ponder")
+	info.StartTime = time.Now()
 
-	if strings.Contains(line, "winc") && pos.Side == board.White {
-		incStr1 := board.RemoveStringToTheLeftOfMarker(line, "winc ")
-		incStr2 := board.RemoveStringToTheRightOfMarker(incStr1, " ")
-		inc, _ = strconv.Atoi(incStr2)
+	timeName, incName := "wtime", "winc"
+	if pos.Side == board.Black {
+		timeName, incName = "btime", "binc"
 	}
 
-	if strings.Contains(line, "wtime") && pos.Side == board.White {
-		timeStr1 := board.RemoveStringToTheLeftOfMarker(line, "wtime ")
-		timeStr2 := board.RemoveStringToTheRightOfMarker(timeStr1, " ")
-		fmt.Println(timeStr1, "|", timeStr2)
-		timeInt, _ = strconv.Atoi(timeStr2)
-	}
+	var limits engine.Limits
+	limits.Time = time.Duration(goParameter(line, timeName)) * time.Millisecond
+	limits.Increment = time.Duration(goParameter(line, incName)) * time.Millisecond
+	limits.MovesToGo = goParameter(line, "movestogo")
+	limits.MoveTime = time.Duration(goParameter(line, "movetime")) * time.Millisecond
+	limits.Depth = goParameter(line, "depth") // only supported by the alpha-beta search
+	limits.Nodes = uint64(goParameter(line, "nodes"))
+	limits.Mate = goParameter(line, "mate")
+	limits.SearchMoves = goMoves(line, "searchmoves", pos)
 
-	if strings.Contains(line, "btime") && pos.Side == board.Black {
-		timeStr1 := board.RemoveStringToTheLeftOfMarker(line, "btime ")
-		timeStr2 := board.RemoveStringToTheRightOfMarker(timeStr1, " ")
-		timeInt, _ = strconv.Atoi(timeStr2)
+	if info.Infinite {
+		// an infinite search only stops on 'stop'
+		limits = engine.Limits{Depth: limits.Depth, Nodes: limits.Nodes, Mate: limits.Mate, SearchMoves: limits.SearchMoves}
 	}
+	info.TimeSet = limits.Time > 0 || limits.MoveTime > 0
+	return limits
+}
 
-	if strings.Contains(line, "movestogo") {
-		movesToGoStr1 := board.RemoveStringToTheLeftOfMarker(line, "movestogo ")
-		movesToGoStr2 := board.RemoveStringToTheRightOfMarker(movesToGoStr1, " ")
-		fmt.Println(movesToGoStr1, "|", movesToGoStr2)
-		movesToGo, _ = strconv.Atoi(movesToGoStr2)
+// goParameter returns the value following name in the go command (0 if it is missing)
+func goParameter(line, name string) int {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == name {
+			value, _ := strconv.Atoi(fields[i+1])
+			return value
+		}
 	}
+	return 0
+}
 
-	if strings.Contains(line, "movetime") {
-		moveTimeStr1 := board.RemoveStringToTheLeftOfMarker(line, "movetime ")
-		moveTimeStr2 := board.RemoveStringToTheRightOfMarker(moveTimeStr1, " ")
-		moveTime, _ = strconv.Atoi(moveTimeStr2)
+// goMoves returns the moves following name in the go command, up to the first word
+// that is not a move of the position (i.e. 'searchmoves e2e4 d2d4')
+func goMoves(line, name string, pos *board.ChessBoard) []int {
+	var moves []int
+	fields := strings.Fields(line)
+	for i := 0; i < len(fields); i++ {
+		if fields[i] != name {
+			continue
+		}
+		for _, field := range fields[i+1:] {
+			move := board.NoMove
+			if len(field) >= 4 {
+				move = pos.ParseMove(field)
+			}
+			if move == board.NoMove {
+				break
+			}
+			moves = append(moves, move)
+		}
+		break
 	}
+	return moves
+}
 
-	if strings.Contains(line, "depth") {
-		// Depth is not supported for MCTS implementation
+// SearchPosition searches a given position until ctx is cancelled or a limit is reached.
+// The progress of the search is reported by the progress handler of the engine
+func SearchPosition(ctx context.Context, e *engine.Engine, pos *board.ChessBoard, info *board.SearchInfo, limits engine.Limits) (bestMove, ponderMove int) {
+	result, err := e.Search(ctx, pos, limits)
+	if err != nil {
+		if info.GameMode == board.UciMode {
+			fmt.Printf("info string %v\n", err)
+		} else {
+			fmt.Println(err)
+		}
+		return board.NoMove, board.NoMove
 	}
 
-	if moveTime != -1 {
-		timeInt = moveTime
-		movesToGo = 1
+	noMate := ""
+	if limits.Mate > 0 && result.Mate == 0 {
+		noMate = fmt.Sprintf("no mate in %d found", limits.Mate)
+		if searched := (result.Info.Depth + 1) / 2; searched < limits.Mate {
+			noMate += fmt.Sprintf(", searched up to mate in %d", searched)
+		}
 	}
 
-	info.StartTime = time.Now()
-
-	if timeInt != -1 {
-		info.TimeSet = true
-		timeInt /= movesToGo
-		// to be on the safe side we remove 50ms from this value
-		timeInt -= 50
-		stopTimeInSeconds := timeInt + inc // find stop time in miliseconds
-		info.StopTime = stopTimeInSeconds
+	if info.GameMode == board.UciMode {
+		if result.Info.Selection != "" {
+			fmt.Printf("info string moveselection %s margin %.3f\n", result.Info.Selection, result.Info.Margin)
+		}
+		if noMate != "" {
+			fmt.Printf("info string %s\n", noMate)
+		}
+	} else if noMate != "" {
+		fmt.Println(noMate)
+	} else if info.PostThinking == true && !result.Book {
+		fmt.Printf("score:%d depth:%d nodes:%d time:%d(ms)\n",
+			result.Score, result.Info.Depth, result.Info.Nodes, result.Info.Time.Milliseconds())
+		if len(result.Info.Lines) > 0 {
+			fmt.Printf("pv %s\n", moveListString(result.Info.Lines[0].PV))
+		}
+		if result.Info.Selection != "" {
+			fmt.Printf("moveselection %s margin %.3f\n", result.Info.Selection, result.Info.Margin)
+		}
 	}
 
-	fmt.Printf("time:%d start:%s stop:%d timeset:%t\n", timeInt, info.StartTime, info.StopTime, info.TimeSet)
-
-	SearchPosition(pos, info)
+	return result.Move, result.PonderMove
 }
 
-// SearchPosition searches a given position
-func SearchPosition(pos *board.ChessBoard, info *board.SearchInfo) int {
-	// ... iterative deepening, search init
-	// for depth = 1 to maxDepth
-	// 		search with alphaBeta if you have enough time left
-	// you do not search to maxDepth from the start but instead search
-	// with depth 1, then 2, then 3 etc, because you first identify
-	// the principle variation or the potentially good moves and in this
-	// way when you search again with more depth you can easily eliminate
-	// a lot of bad nodes automatically
+// wdlString formats the win/draw/loss probabilities (in permille) for an info line
+func wdlString(wdl [3]int) string {
+	return fmt.Sprintf(" wdl %d %d %d", wdl[0], wdl[1], wdl[2])
+}
 
-	// if we can perform a book move, do that first, otherwise perform search
-	bestMove := board.GetBookMove(pos)
-	if bestMove != 0 {
-		PerformMove(pos, info, bestMove)
-		return 0
+// moveListString converts a list of moves to a string in long algebraic notation (i.e. 'e2e4 e7e5')
+func moveListString(moves []int) string {
+	line := make([]string, len(moves))
+	for i, move := range moves {
+		line[i] = board.PrintMove(move)
 	}
+	return strings.Join(line, " ")
+}
 
-	// do normal move search
-	bestScore := 0.0
-	nodes := 0
-	bestMove, bestScore, nodes = uct.GetEngineMoveFast(pos, info)
-
-	// scale from percentage to centipawn loss/gain
-	// here is bestScore from point of view of enemy ?
-	if bestScore < 0.5 { // 0.5 indicates a draw i.e. balanced game
-		bestScore = (0.5 - bestScore) * 100
-	} else {
-		bestScore = (bestScore - 0.5) * 100
-	}
+// printInfo prints the UCI info lines for the best root moves
+func printInfo(progress engine.Info, info *board.SearchInfo) {
+	for idx, line := range progress.Lines {
+		score := fmt.Sprintf("cp %d", line.Score)
+		if line.Mate != 0 {
+			score = fmt.Sprintf("mate %d", line.Mate)
+		}
+		if info.ShowWDL {
+			score += wdlString(line.WDL)
+		}
 
-	moveTime := int64(time.Since(info.StartTime).Seconds() * 1000) // the UCI protocol expects milliseconds
-	if info.GameMode == board.UciMode {
-		fmt.Printf("info score cp %d nodes %d time %d ", int(bestScore), nodes, moveTime)
-	} else if info.PostThinking == true {
-		fmt.Printf("score:%d time:%d(ms)", int(bestScore), moveTime)
-	}
-	fmt.Printf("\n")
-	if info.GameMode == board.UciMode || info.PostThinking == true {
-		// Print the principle variation
-		// todo add ability to print out PV line
-		// pvMoves = GetPvLine(pos, currentDepth)
-		// fmt.Printf("pv")
-		// for pvNum := 0; pvNum < pvMoves; pvNum++ {
-		// 	fmt.Printf(" %s", PrintMove(pos.PvArray[pvNum]))
-		// }
-		// fmt.Println()
-		// fmt.Printf("Ordering: %.2f\n", info.failHighFirst/info.failHigh)
+		fmt.Printf("info depth %d seldepth %d multipv %d score %s nodes %d nps %d hashfull %d time %d pv %s\n",
+			progress.Depth, progress.SelDepth, idx+1, score, progress.Nodes, progress.NPS(),
+			progress.HashFull, progress.Time.Milliseconds(), moveListString(line.PV))
 	}
-
-	PerformMove(pos, info, bestMove)
-
-	return 0
 }
 
 // PerformMove performs the best found move from search or book
-func PerformMove(pos *board.ChessBoard, info *board.SearchInfo, bestMove int) {
+func PerformMove(pos *board.ChessBoard, info *board.SearchInfo, bestMove, ponderMove int) {
 	if info.GameMode == board.UciMode {
-		fmt.Printf("bestmove %s\n", board.PrintMove(bestMove))
-	} else {
+		if bestMove == board.NoMove {
+			// nothing to play (i.e. the game is over), UCI expects a null move
+			fmt.Println("bestmove 0000")
+		} else if ponderMove != board.NoMove {
+			fmt.Printf("bestmove %s ponder %s\n", board.PrintMove(bestMove), board.PrintMove(ponderMove))
+		} else {
+			fmt.Printf("bestmove %s\n", board.PrintMove(bestMove))
+		}
+	} else if bestMove != board.NoMove {
 		fmt.Printf("\n\n***!! Slinky makes move %s !!***\n\n", board.PrintMove(bestMove))
 		pos.MakeMove(bestMove)
 		fmt.Println(pos)
@@ -184,16 +205,99 @@ func ParsePosition(lineIn string, pos *board.ChessBoard) {
 	//fmt.Println(pos)
 }
 
-// UciLoop main UCI loop
-func UciLoop(pos *board.ChessBoard, info *board.SearchInfo) {
+// printUciID prints the engine identification and the supported options
+func printUciID(options []*uciOption) {
 	fmt.Printf("id name %s\n", board.Name)
 	fmt.Printf("id author AngelVI\n")
+	for _, option := range options {
+		fmt.Println(option)
+	}
 	fmt.Println("uciok")
+}
 
-	line := ""
+// searchThread is a search running in the background while the UCI loop keeps reading input
+type searchThread struct {
+	cancel      context.CancelFunc
+	release     chan struct{} // closed once the bestmove may be sent (i.e. on stop or ponderhit)
+	releaseOnce sync.Once
+	done        chan struct{} // closed once the search has finished and the bestmove was sent
+	ponderHit   chan struct{} // closed on ponderhit, the time limits of the search apply from then on
+	ponderOnce  sync.Once
+}
+
+// startSearch starts searching a copy of pos in its own goroutine. When pondering or in
+// infinite mode the bestmove is held back until 'stop' or 'ponderhit' is received
+func startSearch(e *engine.Engine, pos *board.ChessBoard, info *board.SearchInfo, limits engine.Limits) *searchThread {
+	ctx, cancel := context.WithCancel(context.Background())
+	st := &searchThread{
+		cancel:    cancel,
+		release:   make(chan struct{}),
+		done:      make(chan struct{}),
+		ponderHit: make(chan struct{}),
+	}
+	if !info.Ponder && !info.Infinite {
+		st.releaseBestMove()
+	}
+	if info.Ponder {
+		limits.PonderHit = st.ponderHit
+	}
+
+	searchPos := *pos
+	searchInfo := *info
+	e.OnProgress = func(progress engine.Info) { printInfo(progress, &searchInfo) }
+	go func() {
+		defer close(st.done)
+		bestMove, ponderMove := SearchPosition(ctx, e, &searchPos, &searchInfo, limits)
+		// the UCI protocol does not allow a bestmove before the GUI stops pondering/infinite search
+		<-st.release
+		PerformMove(&searchPos, &searchInfo, bestMove, ponderMove)
+	}()
+	return st
+}
 
-	for {
-		line, _ = GetInput("")
+func (st *searchThread) releaseBestMove() {
+	st.releaseOnce.Do(func() { close(st.release) })
+}
+
+// stop stops the search immediately and waits for the bestmove to be sent
+func (st *searchThread) stop() {
+	st.cancel()
+	st.releaseBestMove()
+	<-st.done
+}
+
+// ponderhit switches a pondering search to a normal search which stops once its time budget is used up
+func (st *searchThread) ponderhit(info *board.SearchInfo) {
+	st.releaseBestMove()
+	st.ponderOnce.Do(func() { close(st.ponderHit) })
+	if !info.TimeSet && !info.Infinite {
+		st.cancel()
+	}
+}
+
+// UciLoop main UCI loop
+func UciLoop(pos *board.ChessBoard, info *board.SearchInfo) {
+	uciLoop(InputLines(), pos, info)
+}
+
+// uciLoop runs the UCI protocol on the commands read from lines until 'quit' or until
+// lines is closed
+func uciLoop(lines <-chan string, pos *board.ChessBoard, info *board.SearchInfo) {
+	info.GameMode = board.UciMode
+	e := engine.New()
+	e.Seed = info.Seed
+	options := uciOptions(info, e)
+	printUciID(options)
+
+	var search *searchThread
+	stopSearch := func() {
+		if search != nil {
+			search.stop()
+			search = nil
+		}
+	}
+
+	for line := range lines {
 		if len(line) < 2 {
 			continue
 		}
@@ -201,23 +305,42 @@ func UciLoop(pos *board.ChessBoard, info *board.SearchInfo) {
 		if strings.Contains(line, "isready") {
 			fmt.Println("readyok")
 			continue
+		} else if strings.Contains(line, "setoption") {
+			parseSetOption(line, options)
+		} else if strings.Contains(line, "dumptree") {
+			if err := dumpTree(line, e); err != nil {
+				fmt.Printf("info string %v\n", err)
+			}
+		} else if strings.Contains(line, "ponderhit") {
+			if search != nil {
+				search.ponderhit(info)
+			}
+		} else if strings.Contains(line, "stop") {
+			stopSearch()
 		} else if strings.Contains(line, "position") {
+			stopSearch()
 			ParsePosition(line, pos)
 		} else if strings.Contains(line, "ucinewgame") {
+			stopSearch()
+			e.Clear()
 			ParsePosition("position startpos\n", pos)
 		} else if strings.Contains(line, "go") {
-			ParseGo(line, info, pos)
+			stopSearch()
+			limits := ParseGo(line, info, pos)
+			search = startSearch(e, pos, info, limits)
 		} else if strings.Contains(line, "quit") {
 			info.Quit = true
 			break
 		} else if strings.Contains(line, "uci") {
-			fmt.Printf("id name %s\n", board.Name)
-			fmt.Printf("id author AngelVI\n")
-			fmt.Println("uciok")
+			printUciID(options)
 		}
 
 		if info.Quit {
 			break
 		}
 	}
+
+	stopSearch()
+	// stdin was closed without a quit command -> nothing more to do
+	info.Quit = true
 }
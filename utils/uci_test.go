@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"slinky/board"
+	"slinky/engine"
+	"strings"
+	"testing"
+	"time"
+)
+
+// uciSession runs the UCI loop in the background on the commands sent to it and
+// collects what it prints
+type uciSession struct {
+	t        *testing.T
+	commands chan string
+	output   chan string
+	done     chan struct{}
+	stdout   *os.File
+	pipe     *os.File
+}
+
+// startUCI starts the UCI loop with stdout redirected to the session
+func startUCI(t *testing.T) *uciSession {
+	board.AllInit()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &uciSession{
+		t:        t,
+		commands: make(chan string),
+		output:   make(chan string, 100000),
+		done:     make(chan struct{}),
+		stdout:   os.Stdout,
+		pipe:     w,
+	}
+	os.Stdout = w
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			s.output <- scanner.Text()
+		}
+		close(s.output)
+	}()
+	go func() {
+		defer close(s.done)
+		pos := board.CreateBoard()
+		var info board.SearchInfo
+		uciLoop(s.commands, &pos, &info)
+	}()
+	s.expect("uciok", time.Second)
+	return s
+}
+
+// send sends a command to the UCI loop
+func (s *uciSession) send(command string) {
+	s.commands <- command
+}
+
+// expect waits for a line starting with prefix and returns it
+func (s *uciSession) expect(prefix string, timeout time.Duration) string {
+	s.t.Helper()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line := <-s.output:
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		case <-deadline:
+			s.t.Fatalf("expected %q within %v", prefix, timeout)
+		}
+	}
+}
+
+// expectNone checks that no line starting with prefix is printed for a while
+func (s *uciSession) expectNone(prefix string, wait time.Duration) {
+	s.t.Helper()
+	deadline := time.After(wait)
+	for {
+		select {
+		case line := <-s.output:
+			if strings.HasPrefix(line, prefix) {
+				s.t.Fatalf("unexpected %q", line)
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// quit ends the UCI loop and restores stdout
+func (s *uciSession) quit() {
+	s.send("quit")
+	<-s.done
+	os.Stdout = s.stdout
+	s.pipe.Close()
+}
+
+// TestUciStop checks that an infinite search answers isready while it runs and that
+// stop releases the bestmove at once
+func TestUciStop(t *testing.T) {
+	s := startUCI(t)
+	defer s.quit()
+	s.send("setoption name OwnBook value false")
+	s.send("position startpos moves e2e4")
+	s.send("go infinite")
+
+	s.send("isready")
+	s.expect("readyok", 200*time.Millisecond)
+	// the progress is reported while the search runs
+	info := s.expect("info depth", 2*time.Second)
+	for _, field := range []string{" multipv 1 ", " nodes ", " nps ", " time ", " pv "} {
+		if !strings.Contains(info, field) {
+			t.Errorf("expected%sin the info line %q", field, info)
+		}
+	}
+	s.expectNone("bestmove", 300*time.Millisecond)
+
+	start := time.Now()
+	s.send("stop")
+	s.expect("bestmove", time.Second)
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("the bestmove took %v after stop", elapsed)
+	}
+}
+
+// TestUciPonderhit checks that a pondering search ignores the clock until ponderhit and
+// stops by the clock after it
+func TestUciPonderhit(t *testing.T) {
+	s := startUCI(t)
+	defer s.quit()
+	s.send("setoption name OwnBook value false")
+	s.send("setoption name Move Overhead value 0")
+	s.send("position startpos moves e2e4 e7e5")
+	// the clock allows for about 25ms per move
+	s.send("go ponder wtime 1000 btime 1000")
+	s.expectNone("bestmove", 300*time.Millisecond)
+
+	start := time.Now()
+	s.send("ponderhit")
+	s.expect("bestmove", time.Second)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("the bestmove took %v after ponderhit with 1s on the clock", elapsed)
+	}
+}
+
+// captureOutput returns what f prints to stdout
+func captureOutput(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+	output, _ := io.ReadAll(r)
+	return string(output)
+}
+
+// TestPrintInfo checks the format of the info lines, one per reported root move
+func TestPrintInfo(t *testing.T) {
+	board.AllInit()
+	pos := board.CreateBoard()
+	pos.ParseFen(board.StartFen)
+	e2e4, d2d4 := pos.ParseMove("e2e4"), pos.ParseMove("d2d4")
+	pos.MakeMove(e2e4)
+	e7e5 := pos.ParseMove("e7e5")
+
+	progress := engine.Info{
+		Depth:    4,
+		SelDepth: 9,
+		Nodes:    3000,
+		Time:     1500 * time.Millisecond,
+		HashFull: 12,
+		Lines: []engine.Line{
+			{Move: e2e4, Score: 35, WDL: [3]int{400, 450, 150}, PV: []int{e2e4, e7e5}},
+			{Move: d2d4, Mate: -3, WDL: [3]int{0, 0, 1000}, PV: []int{d2d4}},
+		},
+	}
+	info := board.SearchInfo{ShowWDL: true}
+	output := captureOutput(t, func() { printInfo(progress, &info) })
+	expected := "info depth 4 seldepth 9 multipv 1 score cp 35 wdl 400 450 150 nodes 3000 nps 2000 hashfull 12 time 1500 pv e2e4 e7e5\n" +
+		"info depth 4 seldepth 9 multipv 2 score mate -3 wdl 0 0 1000 nodes 3000 nps 2000 hashfull 12 time 1500 pv d2d4\n"
+	if output != expected {
+		t.Errorf("expected\n%sgot\n%s", expected, output)
+	}
+}